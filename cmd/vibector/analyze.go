@@ -1,28 +1,33 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anisimov-anthony/vibector/internal/analyzer"
-	"github.com/anisimov-anthony/vibector/internal/config"
 	"github.com/anisimov-anthony/vibector/internal/detector"
 	"github.com/anisimov-anthony/vibector/internal/git"
 	"github.com/anisimov-anthony/vibector/internal/metrics"
 	"github.com/anisimov-anthony/vibector/internal/reporter"
+	"github.com/anisimov-anthony/vibector/internal/state"
 )
 
 var (
-	analyzeOutput              string
-	analyzeSuspiciousAdditions int64
-	analyzeSuspiciousDeletions int64
-	analyzeMaxAdditionsMin     float64
-	analyzeMaxDeletionsMin     float64
-	analyzeMinTimeDelta        int64
-	analyzeBranch              string
-	analyzeExcludeFiles        []string
+	analyzeFlags        analysisFlags
+	analyzeOutput       string
+	analyzeCloneDepth   int
+	analyzeIncremental  bool
+	analyzeStateFile    string
+	analyzeOnlyUnsigned bool
+	analyzeSubmodules   bool
 )
 
 var analyzeCmd = &cobra.Command{
@@ -31,64 +36,73 @@ var analyzeCmd = &cobra.Command{
 	Long: `Analyze a git repository to detect commits that may have been generated by AI.
 
 The repository argument should be a local directory path to a git repository
+or a remote URL (https://, ssh://, file:// or git@host:owner/repo). Remote
+repositories are cloned into memory and discarded after the analysis.
+A local path may be any directory inside a working tree, a linked worktree or
+a bare repository. With --git-dir the git directory is given explicitly and the
+repository argument, if any, is its working tree
 
 Requires threshold configuration via flags or config file`,
-	Args: cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if analyzeFlags.gitDir != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runAnalyze,
 }
 
 func init() {
 	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "", "output file path (required, format detected from extension: .txt or .json)")
 	_ = analyzeCmd.MarkFlagRequired("output")
-	analyzeCmd.Flags().Int64Var(&analyzeSuspiciousAdditions, "suspicious-additions", 0, "flag commits with more than this many additions (0 to disable)")
-	analyzeCmd.Flags().Int64Var(&analyzeSuspiciousDeletions, "suspicious-deletions", 0, "flag commits with more than this many deletions (0 to disable)")
-	analyzeCmd.Flags().Float64Var(&analyzeMaxAdditionsMin, "max-additions-pm", 0, "max additions per minute (0 to disable)")
-	analyzeCmd.Flags().Float64Var(&analyzeMaxDeletionsMin, "max-deletions-pm", 0, "max deletions per minute (0 to disable)")
-	analyzeCmd.Flags().Int64Var(&analyzeMinTimeDelta, "min-time-delta", 0, "min seconds between commits (0 to disable)")
-	analyzeCmd.Flags().StringVar(&analyzeBranch, "branch", "", "branch to analyze")
-	analyzeCmd.Flags().StringSliceVar(&analyzeExcludeFiles, "exclude-files", []string{}, "file patterns to exclude (e.g., *.log,*.tmp)")
+	analyzeFlags.addThresholdFlags(analyzeCmd)
+	analyzeFlags.addHistoryFlags(analyzeCmd)
+	analyzeFlags.addDiffFlags(analyzeCmd)
+	analyzeFlags.addCacheFlags(analyzeCmd)
+	analyzeCmd.Flags().IntVar(&analyzeCloneDepth, "clone-depth", 0, "history depth when cloning a remote repository (0 for full history)")
+	analyzeCmd.Flags().BoolVar(&analyzeIncremental, "incremental", false, "only analyze commits added since the run recorded in the state file")
+	analyzeCmd.Flags().StringVar(&analyzeStateFile, "state", ".vibector-state.json", "state file used by --incremental")
+	analyzeCmd.Flags().BoolVar(&analyzeOnlyUnsigned, "only-unsigned", false, "only report suspicious commits whose signature does not verify against --keyring, including unsigned ones (requires a keyring)")
+	analyzeCmd.Flags().BoolVar(&analyzeSubmodules, "submodules", false, "also analyze the history of initialized submodules, recursively")
+	analyzeCmd.Flags().StringVar(&analyzeFlags.gitDir, "git-dir", "", "git directory to analyze, with the repository argument as its working tree if given")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
-	repoPath := args[0]
+	var repoPath string
+	if len(args) > 0 {
+		repoPath = args[0]
+	}
 
 	outputFormat, err := detectFormatFromExtension(analyzeOutput)
 	if err != nil {
 		return err
 	}
 
-	cfg, err := config.Load(cfgFile)
+	options, err := analyzeFlags.options(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	if cmd.Flags().Changed("suspicious-additions") {
-		cfg.Thresholds.SuspiciousAdditions = analyzeSuspiciousAdditions
-	}
-	if cmd.Flags().Changed("suspicious-deletions") {
-		cfg.Thresholds.SuspiciousDeletions = analyzeSuspiciousDeletions
-	}
-	if cmd.Flags().Changed("max-additions-pm") {
-		cfg.Thresholds.MaxAdditionsPerMin = analyzeMaxAdditionsMin
-	}
-	if cmd.Flags().Changed("max-deletions-pm") {
-		cfg.Thresholds.MaxDeletionsPerMin = analyzeMaxDeletionsMin
-	}
-	if cmd.Flags().Changed("min-time-delta") {
-		cfg.Thresholds.MinTimeDeltaSeconds = analyzeMinTimeDelta
-	}
-	if cmd.Flags().Changed("exclude-files") {
-		cfg.ExcludeFiles = analyzeExcludeFiles
+	// Without a keyring no signature can be verified, so a forged one would
+	// pass as signed.
+	if analyzeOnlyUnsigned && options.Repository.Keyring == "" {
+		return fmt.Errorf("--only-unsigned requires --keyring or keyring_file in the config file")
 	}
 
-	if cfg.Thresholds.IsZero() {
-		return fmt.Errorf("no thresholds configured - please set thresholds via config file or flags")
+	if analyzeSubmodules && analyzeIncremental {
+		return fmt.Errorf("--submodules cannot be combined with --incremental")
 	}
 
-	repoOpts := &git.RepositoryOptions{
-		ExcludeFiles: cfg.ExcludeFiles,
+	// A limited walk would leave the older history out of the accumulated
+	// statistics for good, since later runs skip everything before its tips.
+	if analyzeIncremental && (options.Commits.MaxDepth > 0 || !options.Commits.Since.IsZero() || !options.Commits.Until.IsZero()) {
+		return fmt.Errorf("--incremental cannot be combined with --max-depth, --since or --until")
 	}
 
+	opts := options.Commits
+	repoOpts := options.Repository
+	repoOpts.CloneDepth = analyzeCloneDepth
+
 	repo, err := git.OpenRepository(repoPath, repoOpts)
 	if err != nil {
 		return fmt.Errorf("failed to open repository: %w", err)
@@ -96,26 +110,86 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	defer func() { _ = repo.Close() }()
 
 	a := analyzer.New(repo)
-	opts := &git.CommitOptions{
-		Branch: analyzeBranch,
+
+	var previous *state.State
+	var tips map[string]string
+	var stateRepository, fingerprint string
+	if analyzeIncremental {
+		// The git directory identifies the repository in the state file,
+		// whichever path or working tree it was analyzed from.
+		stateRepository, err = a.Location()
+		if err != nil {
+			return err
+		}
+
+		var diffFingerprint string
+		diffFingerprint, err = a.Fingerprint()
+		if err != nil {
+			return err
+		}
+		fingerprint = stateFingerprint(diffFingerprint, repoOpts, opts)
+
+		previous, err = state.Load(analyzeStateFile)
+		if err != nil {
+			return err
+		}
+		if previous != nil && previous.Repository != stateRepository {
+			return fmt.Errorf("state file %s belongs to %s, not %s", analyzeStateFile, previous.Repository, stateRepository)
+		}
+		if previous != nil && previous.Fingerprint != fingerprint {
+			fmt.Fprintln(os.Stderr, "Warning: analysis settings changed since the previous run, analyzing the full history again")
+			previous = nil
+		}
+		opts.ExcludeRevisions = previous.Revisions()
+
+		tips, err = a.Tips(opts)
+		if err != nil {
+			return fmt.Errorf("failed to resolve analyzed refs: %w", err)
+		}
 	}
 
 	fmt.Fprintln(os.Stderr, "Analyzing repository...")
 	result, err := a.AnalyzeRepository(opts)
+	if previous != nil && errors.Is(err, git.ErrUnknownExcludedRevision) {
+		// The history was rewritten since the previous run, so its statistics
+		// cannot be merged with the new commits.
+		fmt.Fprintf(os.Stderr, "Warning: %v, analyzing the full history again\n", errors.Unwrap(err))
+		previous = nil
+		opts.ExcludeRevisions = nil
+		result, err = a.AnalyzeRepository(opts)
+	}
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
 
+	if result.ShallowCommits > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: shallow clone, %d boundary commit(s) could not be analyzed\n", result.ShallowCommits)
+	}
+
 	fmt.Fprintln(os.Stderr, "Calculating statistics...")
 	stats := metrics.CalculateStats(result.Commits, result.CommitPairs)
+	if previous != nil {
+		stats = metrics.MergeStats(previous.Stats, stats)
+	}
 
 	fmt.Fprintln(os.Stderr, "Detecting suspicious commits...")
-	det, err := detector.New(&cfg.Thresholds)
+	det, err := detector.New(options.Thresholds)
 	if err != nil {
 		return fmt.Errorf("failed to create detector: %w", err)
 	}
 
-	suspicious := det.DetectSuspicious(result.CommitPairs, stats)
+	suspicious := det.DetectSuspicious(result.Commits, result.CommitPairs, stats)
+	if analyzeOnlyUnsigned {
+		suspicious = detector.FilterUnsigned(suspicious)
+	}
+
+	var submodules []*reporter.SubmoduleReport
+	if analyzeSubmodules {
+		submodules, err = analyzeSubmoduleHistories(a, "", repoOpts, opts, det)
+		if err != nil {
+			return err
+		}
+	}
 
 	rep, err := reporter.NewReporter(outputFormat)
 	if err != nil {
@@ -123,9 +197,18 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	}
 
 	reportData := &reporter.ReportData{
-		Suspicious: suspicious,
-		Stats:      stats,
-		Thresholds: &cfg.Thresholds,
+		Suspicious:     suspicious,
+		Stats:          stats,
+		Thresholds:     options.Thresholds,
+		ShallowCommits: result.ShallowCommits,
+		OnlyUnsigned:   analyzeOnlyUnsigned,
+		Submodules:     submodules,
+	}
+	if analyzeIncremental {
+		reportData.Incremental = &reporter.IncrementalRun{NewCommits: len(result.Commits)}
+		if previous != nil {
+			reportData.Incremental.PreviousRun = previous.UpdatedAt
+		}
 	}
 
 	reportStr, err := rep.Generate(reportData)
@@ -138,5 +221,145 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Fprintf(os.Stderr, "Report written to %s\n", analyzeOutput)
 
+	if analyzeIncremental {
+		if err := saveState(analyzeStateFile, stateRepository, fingerprint, previous, tips, stats); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "State written to %s\n", analyzeStateFile)
+	}
+
+	return nil
+}
+
+// analyzeSubmoduleHistories analyzes the initialized submodules of the
+// repository a was created for and theirs in turn with the superproject's options
+// and detector. Branch and ref selection only apply to the superproject, so
+// each submodule's HEAD is walked.
+func analyzeSubmoduleHistories(a *analyzer.Analyzer, prefix string, repoOpts *git.RepositoryOptions, opts *git.CommitOptions, det *detector.Detector) ([]*reporter.SubmoduleReport, error) {
+	submodules, err := a.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+
+	subRepoOpts := *repoOpts
+	subRepoOpts.IncludePaths = nil
+	subRepoOpts.GitDir = ""
+
+	subOpts := &git.CommitOptions{
+		MaxDepth:   opts.MaxDepth,
+		TimeSource: opts.TimeSource,
+		Since:      opts.Since,
+		Until:      opts.Until,
+	}
+
+	var reports []*reporter.SubmoduleReport
+	for _, sm := range submodules {
+		subPath := sm.Path
+		if prefix != "" {
+			subPath = prefix + "/" + sm.Path
+		}
+		fmt.Fprintf(os.Stderr, "Analyzing submodule %s...\n", subPath)
+
+		nested, err := analyzeSubmodule(sm.Dir, subPath, sm.Name, &subRepoOpts, subOpts, det)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, nested...)
+	}
+
+	return reports, nil
+}
+
+func analyzeSubmodule(repoPath, subPath, name string, repoOpts *git.RepositoryOptions, opts *git.CommitOptions, det *detector.Detector) ([]*reporter.SubmoduleReport, error) {
+	repo, err := git.OpenRepository(repoPath, repoOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open submodule %s: %w", subPath, err)
+	}
+	defer func() { _ = repo.Close() }()
+
+	a := analyzer.New(repo)
+	result, err := a.AnalyzeRepository(opts)
+	if err != nil {
+		return nil, fmt.Errorf("analysis of submodule %s failed: %w", subPath, err)
+	}
+
+	stats := metrics.CalculateStats(result.Commits, result.CommitPairs)
+	suspicious := det.DetectSuspicious(result.Commits, result.CommitPairs, stats)
+	if analyzeOnlyUnsigned {
+		suspicious = detector.FilterUnsigned(suspicious)
+	}
+
+	nested, err := analyzeSubmoduleHistories(a, subPath, repoOpts, opts, det)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &reporter.SubmoduleReport{
+		Name:           name,
+		Path:           subPath,
+		Suspicious:     suspicious,
+		Stats:          stats,
+		ShallowCommits: result.ShallowCommits,
+	}
+	return append([]*reporter.SubmoduleReport{report}, nested...), nil
+}
+
+// saveState records the analyzed tips, keeping those of refs that have since
+// disappeared so their commits are not counted again, and the merged stats.
+func saveState(path, repoPath, fingerprint string, previous *state.State, tips map[string]string, stats *metrics.RepositoryStats) error {
+	next := &state.State{
+		Repository:  repoPath,
+		Fingerprint: fingerprint,
+		Tips:        make(map[string]string),
+		Stats:       stats,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if previous != nil {
+		for name, hash := range previous.Tips {
+			next.Tips[name] = hash
+		}
+	}
+	for name, hash := range tips {
+		next.Tips[name] = hash
+	}
+
+	if err := state.Save(path, next); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
 	return nil
 }
+
+// stateFingerprint identifies the settings accumulated statistics depend on:
+// the diff settings of the repository and the selected history.
+func stateFingerprint(diff string, repoOpts *git.RepositoryOptions, opts *git.CommitOptions) string {
+	timeSource := opts.TimeSource
+	if timeSource == "" {
+		timeSource = git.TimeSourceAuthor
+	}
+	refs := append([]string(nil), opts.RefPatterns...)
+	sort.Strings(refs)
+
+	data, _ := json.Marshal(struct {
+		Diff          string
+		IncludeMerges bool
+		MailmapFile   string
+		Branch        string
+		Range         string
+		AllRefs       bool
+		RefPatterns   []string
+		TimeSource    git.TimeSource
+	}{
+		Diff:          diff,
+		IncludeMerges: repoOpts.IncludeMerges,
+		MailmapFile:   repoOpts.MailmapFile,
+		Branch:        opts.Branch,
+		Range:         opts.Range,
+		AllRefs:       opts.AllRefs || len(refs) > 0,
+		RefPatterns:   refs,
+		TimeSource:    timeSource,
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
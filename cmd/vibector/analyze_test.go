@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/anisimov-anthony/vibector/internal/git"
+)
+
+func TestStateFingerprint(t *testing.T) {
+	base := stateFingerprint("diff", &git.RepositoryOptions{}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a", "refs/heads/b"}})
+
+	tests := []struct {
+		name     string
+		diff     string
+		repoOpts *git.RepositoryOptions
+		opts     *git.CommitOptions
+		wantSame bool
+	}{
+		{"same settings", "diff", &git.RepositoryOptions{}, &git.CommitOptions{RefPatterns: []string{"refs/heads/b", "refs/heads/a"}, AllRefs: true, TimeSource: git.TimeSourceAuthor}, true},
+		{"diff settings", "other", &git.RepositoryOptions{}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a", "refs/heads/b"}}, false},
+		{"merges", "diff", &git.RepositoryOptions{IncludeMerges: true}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a", "refs/heads/b"}}, false},
+		{"mailmap", "diff", &git.RepositoryOptions{MailmapFile: "team.mailmap"}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a", "refs/heads/b"}}, false},
+		{"refs", "diff", &git.RepositoryOptions{}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a"}}, false},
+		{"branch", "diff", &git.RepositoryOptions{}, &git.CommitOptions{Branch: "main"}, false},
+		{"time source", "diff", &git.RepositoryOptions{}, &git.CommitOptions{RefPatterns: []string{"refs/heads/a", "refs/heads/b"}, TimeSource: git.TimeSourceCommitter}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stateFingerprint(tt.diff, tt.repoOpts, tt.opts)
+			if (got == base) != tt.wantSame {
+				t.Errorf("stateFingerprint() = %s, base %s, want same = %v", got, base, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anisimov-anthony/vibector/internal/analyzer"
+	"github.com/anisimov-anthony/vibector/internal/detector"
+	"github.com/anisimov-anthony/vibector/internal/git"
+	"github.com/anisimov-anthony/vibector/internal/metrics"
+	"github.com/anisimov-anthony/vibector/internal/reporter"
+)
+
+var (
+	annotateFlags     analysisFlags
+	annotateNotesRef  string
+	annotateOverwrite bool
+	annotateDryRun    bool
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate [repository]",
+	Short: "Write suspicious commits to git notes",
+	Long: `Analyze a local repository and attach a git note with the reasons and score
+to every suspicious commit, under refs/notes/vibector by default. The notes
+show up in git log --notes=vibector and can be pushed like any other ref.
+
+This is the only command that writes to the repository. Only the notes ref is
+updated, in a single commit; history and working tree are left untouched.
+
+The repository may be any directory inside a working tree, a linked worktree
+or a bare repository. With --git-dir the git directory is given explicitly,
+and the repository argument is its working tree.
+
+Commits that already have a note are skipped unless --overwrite is given.
+Notes with a "Reviewed-by:" line are never rewritten`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAnnotate,
+}
+
+func init() {
+	annotateCmd.Flags().StringVar(&annotateNotesRef, "notes-ref", git.DefaultNotesRef, "notes ref to read and write")
+	annotateCmd.Flags().BoolVar(&annotateOverwrite, "overwrite", false, "replace existing notes that were not marked as reviewed")
+	annotateCmd.Flags().BoolVar(&annotateDryRun, "dry-run", false, "print the notes instead of writing them")
+	annotateFlags.addThresholdFlags(annotateCmd)
+	annotateFlags.addHistoryFlags(annotateCmd)
+	annotateFlags.addDiffFlags(annotateCmd)
+	annotateFlags.addCacheFlags(annotateCmd)
+	annotateCmd.Flags().StringVar(&annotateFlags.gitDir, "git-dir", "", "git directory to annotate, with the repository argument as its working tree")
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) error {
+	repoPath := "."
+	if len(args) > 0 {
+		repoPath = args[0]
+	}
+
+	options, err := annotateFlags.options(cmd)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.OpenRepository(repoPath, options.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer func() { _ = repo.Close() }()
+
+	a := analyzer.New(repo)
+
+	existing, err := a.ReadNotes(annotateNotesRef)
+	if err != nil {
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "Analyzing repository...")
+	result, err := a.AnalyzeRepository(options.Commits)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+
+	det, err := detector.New(options.Thresholds)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+
+	stats := metrics.CalculateStats(result.Commits, result.CommitPairs)
+	suspicious := det.DetectSuspicious(result.Commits, result.CommitPairs, stats)
+
+	notes := make(map[string]string)
+	var annotated, reviewed int
+	for _, s := range suspicious {
+		hash := s.Pair.Current.Hash
+		if note, ok := existing[hash]; ok {
+			if reviewer, ok := reporter.NoteReviewer(note); ok {
+				fmt.Printf("%s reviewed by %s, skipped\n", hash[:7], reviewer)
+				reviewed++
+				continue
+			}
+			if !annotateOverwrite {
+				annotated++
+				continue
+			}
+		}
+		notes[hash] = reporter.FormatNote(s)
+	}
+
+	if annotateDryRun {
+		for _, s := range suspicious {
+			if note, ok := notes[s.Pair.Current.Hash]; ok {
+				fmt.Printf("commit %s\n%s\n", s.Pair.Current.Hash, note)
+			}
+		}
+		fmt.Printf("Would write %d note(s) to %s.\n", len(notes), annotateNotesRef)
+		return nil
+	}
+
+	message := fmt.Sprintf("Notes added by 'vibector annotate'\n\n%d suspicious commit(s) annotated.\n", len(notes))
+	if err := a.WriteNotes(annotateNotesRef, notes, message); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+
+	fmt.Printf("Wrote %d note(s) to %s (%d already annotated, %d reviewed).\n", len(notes), annotateNotesRef, annotated, reviewed)
+	if len(notes) > 0 {
+		fmt.Printf("Show them with: git log --notes=%s\n", shortNotesRef(annotateNotesRef))
+	}
+
+	return nil
+}
+
+// shortNotesRef strips refs/notes/ like git log --notes expects.
+func shortNotesRef(ref string) string {
+	const prefix = "refs/notes/"
+	return strings.TrimPrefix(ref, prefix)
+}
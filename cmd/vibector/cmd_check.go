@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anisimov-anthony/vibector/internal/analyzer"
+	"github.com/anisimov-anthony/vibector/internal/detector"
+	"github.com/anisimov-anthony/vibector/internal/git"
+)
+
+var (
+	checkFlags  analysisFlags
+	checkStaged bool
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [repository]",
+	Short: "Check uncommitted changes before committing",
+	Long: `Check the pending change of a local repository against the thresholds, as if
+it was committed now. The tracked files of the working tree, or the index with
+--staged, are diffed against HEAD and the time since the last commit is used
+as the time delta.
+
+The repository may be given as any directory inside its working tree. With
+--git-dir the git directory is given explicitly, and the repository argument
+is its working tree.
+
+Exits with a non-zero status when the change would be flagged, so it can be
+used as a pre-commit hook`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheck,
+}
+
+func init() {
+	checkCmd.Flags().BoolVar(&checkStaged, "staged", false, "check the staged changes instead of the working tree")
+	checkFlags.addThresholdFlags(checkCmd)
+	checkFlags.addDiffFlags(checkCmd)
+	checkCmd.Flags().StringVar(&checkFlags.gitDir, "git-dir", "", "git directory to check, with the repository argument as its working tree")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	repoPath := "."
+	if len(args) > 0 {
+		repoPath = args[0]
+	}
+
+	options, err := checkFlags.options(cmd)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.OpenRepository(repoPath, options.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer func() { _ = repo.Close() }()
+
+	pair, err := analyzer.New(repo).Pending(&git.PendingOptions{
+		Staged:     checkStaged,
+		TimeSource: options.Commits.TimeSource,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get pending changes: %w", err)
+	}
+
+	what := "working tree changes"
+	if checkStaged {
+		what = "staged changes"
+	}
+
+	if pair == nil {
+		fmt.Printf("No commits yet, %s not checked.\n", what)
+		return nil
+	}
+	if pair.Stats.TotalAdditions == 0 && pair.Stats.TotalDeletions == 0 {
+		fmt.Printf("No %s.\n", what)
+		return nil
+	}
+
+	det, err := detector.New(options.Thresholds)
+	if err != nil {
+		return fmt.Errorf("failed to create detector: %w", err)
+	}
+
+	fmt.Printf("Checking %s: %d additions, %d deletions in %d file(s), %s since the last commit (%s)\n",
+		what, pair.Stats.Additions, pair.Stats.Deletions, pair.Stats.FilesChanged,
+		detector.FormatTimeDelta(pair.TimeDelta), pair.Previous.Hash[:7])
+
+	suspicious := det.DetectSuspicious(nil, []*git.CommitPair{pair}, nil)
+	if len(suspicious) == 0 {
+		fmt.Println("OK: the change would not be flagged.")
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "The change would be flagged as suspicious:")
+	for _, reason := range suspicious[0].Reasons {
+		fmt.Fprintf(os.Stderr, "  - %s\n", reason)
+	}
+
+	cmd.SilenceUsage = true
+	return fmt.Errorf("pending change exceeds the thresholds")
+}
@@ -28,5 +28,5 @@ by examining commit patterns, code velocity, and statistical anomalies`,
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file path")
-	rootCmd.AddCommand(analyzeCmd, configCmd)
+	rootCmd.AddCommand(analyzeCmd, annotateCmd, checkCmd, configCmd)
 }
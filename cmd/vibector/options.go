@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anisimov-anthony/vibector/internal/config"
+	"github.com/anisimov-anthony/vibector/internal/detector"
+	"github.com/anisimov-anthony/vibector/internal/git"
+)
+
+// analysisFlags are the flags analyze, annotate and check share. Each command
+// registers the groups it supports; the flags of the other groups keep their
+// zero value.
+type analysisFlags struct {
+	suspiciousAdditions int64
+	suspiciousDeletions int64
+	maxAdditionsMin     float64
+	maxDeletionsMin     float64
+	minTimeDelta        int64
+	timeSource          string
+
+	branch           string
+	revisionRange    string
+	since            string
+	until            string
+	allRefs          bool
+	refs             []string
+	maxDepth         int
+	paths            []string
+	includeMerges    bool
+	maxTimestampSkew int64
+	mailmap          string
+	keyring          string
+
+	excludeFiles     []string
+	renameSimilarity int
+	noRenames        bool
+	ignoreFormatting bool
+	jobs             int
+
+	cache    bool
+	noCache  bool
+	cacheDir string
+
+	gitDir string
+}
+
+// analysisOptions are the settings of a run: the configuration file with the
+// flags that were set applied on top.
+type analysisOptions struct {
+	Thresholds *detector.Thresholds
+	Repository *git.RepositoryOptions
+	Commits    *git.CommitOptions
+}
+
+// addThresholdFlags registers the thresholds that apply to a single change.
+func (f *analysisFlags) addThresholdFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&f.suspiciousAdditions, "suspicious-additions", 0, "flag commits with more than this many additions (0 to disable)")
+	cmd.Flags().Int64Var(&f.suspiciousDeletions, "suspicious-deletions", 0, "flag commits with more than this many deletions (0 to disable)")
+	cmd.Flags().Float64Var(&f.maxAdditionsMin, "max-additions-pm", 0, "max additions per minute (0 to disable)")
+	cmd.Flags().Float64Var(&f.maxDeletionsMin, "max-deletions-pm", 0, "max deletions per minute (0 to disable)")
+	cmd.Flags().Int64Var(&f.minTimeDelta, "min-time-delta", 0, "min seconds between commits (0 to disable)")
+	cmd.Flags().StringVar(&f.timeSource, "time-source", "author", "timestamp used for time deltas: author or committer")
+}
+
+// addHistoryFlags registers the flags selecting the commits to analyze and how
+// their authors and signatures are read.
+func (f *analysisFlags) addHistoryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.branch, "branch", "", "branch to analyze")
+	cmd.Flags().StringVar(&f.revisionRange, "range", "", "revision range to analyze (e.g., v2.0..HEAD or main..feature)")
+	cmd.Flags().StringVar(&f.since, "since", "", "only analyze commits after this date (YYYY-MM-DD, RFC3339 or relative like 14d)")
+	cmd.Flags().StringVar(&f.until, "until", "", "only analyze commits before this date (YYYY-MM-DD, RFC3339 or relative like 14d)")
+	cmd.Flags().BoolVar(&f.allRefs, "all-refs", false, "analyze all branches, remote-tracking branches and tags")
+	cmd.Flags().StringSliceVar(&f.refs, "refs", []string{}, "ref glob patterns to analyze (e.g., refs/heads/feature/*), implies --all-refs")
+	cmd.Flags().IntVar(&f.maxDepth, "max-depth", 0, "maximum number of commits to analyze (0 for no limit)")
+	cmd.Flags().StringSliceVar(&f.paths, "path", []string{}, "only analyze commits and changes under these paths (e.g., services/billing)")
+	cmd.Flags().BoolVar(&f.includeMerges, "include-merges", false, "also analyze merge commits against their first parent")
+	cmd.Flags().Int64Var(&f.maxTimestampSkew, "max-timestamp-skew", 0, "max seconds between author and committer time before flagging rewritten history (0 to disable)")
+	cmd.Flags().StringVar(&f.mailmap, "mailmap", "", "extra mailmap file applied after the repository's .mailmap")
+	cmd.Flags().StringVar(&f.keyring, "keyring", "", "file of armored OpenPGP public keys and/or SSH allowed signers to verify commit signatures with")
+}
+
+// addDiffFlags registers the flags deciding which lines of a change count.
+func (f *analysisFlags) addDiffFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.excludeFiles, "exclude-files", []string{}, "gitignore-style patterns to exclude (e.g., *.log,vendor/**,!keep.log)")
+	cmd.Flags().IntVar(&f.renameSimilarity, "rename-similarity", git.DefaultRenameSimilarity, "percentage of shared content for a file to count as renamed or copied")
+	cmd.Flags().BoolVar(&f.noRenames, "no-renames", false, "disable rename and copy detection")
+	cmd.Flags().BoolVar(&f.ignoreFormatting, "ignore-formatting", false, "do not count whitespace and formatting-only lines as additions or deletions")
+	cmd.Flags().IntVarP(&f.jobs, "jobs", "j", 0, "number of diffs computed in parallel (0 for one per CPU)")
+}
+
+// addCacheFlags registers the flags of the diff statistics cache. Commands
+// without them do not use the cache.
+func (f *analysisFlags) addCacheFlags(cmd *cobra.Command) {
+	f.cache = true
+	cmd.Flags().BoolVar(&f.noCache, "no-cache", false, "do not read or write the diff statistics cache")
+	cmd.Flags().StringVar(&f.cacheDir, "cache-dir", "", "diff statistics cache directory (default: $XDG_CACHE_HOME/vibector)")
+}
+
+// options loads the configuration file and applies the flags set on cmd on
+// top of it.
+func (f *analysisFlags) options(cmd *cobra.Command) (*analysisOptions, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("suspicious-additions") {
+		cfg.Thresholds.SuspiciousAdditions = f.suspiciousAdditions
+	}
+	if flags.Changed("suspicious-deletions") {
+		cfg.Thresholds.SuspiciousDeletions = f.suspiciousDeletions
+	}
+	if flags.Changed("max-additions-pm") {
+		cfg.Thresholds.MaxAdditionsPerMin = f.maxAdditionsMin
+	}
+	if flags.Changed("max-deletions-pm") {
+		cfg.Thresholds.MaxDeletionsPerMin = f.maxDeletionsMin
+	}
+	if flags.Changed("min-time-delta") {
+		cfg.Thresholds.MinTimeDeltaSeconds = f.minTimeDelta
+	}
+	if flags.Changed("max-timestamp-skew") {
+		cfg.Thresholds.MaxTimestampSkewSeconds = f.maxTimestampSkew
+	}
+	if flags.Changed("exclude-files") {
+		cfg.ExcludeFiles = f.excludeFiles
+	}
+	if flags.Changed("mailmap") {
+		cfg.MailmapFile = f.mailmap
+	}
+	if flags.Changed("keyring") {
+		cfg.KeyringFile = f.keyring
+	}
+
+	if cfg.Thresholds.IsZero() {
+		return nil, fmt.Errorf("no thresholds configured - please set thresholds via config file or flags")
+	}
+
+	commitOpts := &git.CommitOptions{
+		Branch:      f.branch,
+		MaxDepth:    f.maxDepth,
+		TimeSource:  git.TimeSource(f.timeSource),
+		Range:       f.revisionRange,
+		AllRefs:     f.allRefs,
+		RefPatterns: f.refs,
+	}
+
+	now := time.Now()
+	if f.since != "" {
+		if commitOpts.Since, err = parseDate(f.since, now); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if f.until != "" {
+		if commitOpts.Until, err = parseUntil(f.until, now); err != nil {
+			return nil, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	repoOpts := &git.RepositoryOptions{
+		ExcludeFiles:  cfg.ExcludeFiles,
+		IncludeMerges: f.includeMerges,
+		IncludePaths:  f.paths,
+		CloneBranch:   f.branch,
+
+		RenameSimilarity: f.renameSimilarity,
+		DisableRenames:   f.noRenames,
+		IgnoreFormatting: f.ignoreFormatting,
+		Languages:        cfg.Languages,
+		MailmapFile:      cfg.MailmapFile,
+		Keyring:          cfg.KeyringFile,
+		Jobs:             f.jobs,
+		GitDir:           f.gitDir,
+	}
+
+	if f.cache && !f.noCache {
+		repoOpts.CacheDir, err = cacheDir(f.cacheDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &analysisOptions{
+		Thresholds: &cfg.Thresholds,
+		Repository: repoOpts,
+		Commits:    commitOpts,
+	}, nil
+}
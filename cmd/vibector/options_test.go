@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestAnalysisFlagsRegistration(t *testing.T) {
+	groups := map[string]func(*analysisFlags, *cobra.Command){
+		"threshold": (*analysisFlags).addThresholdFlags,
+		"history":   (*analysisFlags).addHistoryFlags,
+		"diff":      (*analysisFlags).addDiffFlags,
+		"cache":     (*analysisFlags).addCacheFlags,
+	}
+
+	commands := map[string]struct {
+		cmd    *cobra.Command
+		groups []string
+	}{
+		"analyze":  {analyzeCmd, []string{"threshold", "history", "diff", "cache"}},
+		"annotate": {annotateCmd, []string{"threshold", "history", "diff", "cache"}},
+		"check":    {checkCmd, []string{"threshold", "diff"}},
+	}
+
+	for name, c := range commands {
+		t.Run(name, func(t *testing.T) {
+			registered := make(map[string]bool)
+			for _, group := range c.groups {
+				registered[group] = true
+			}
+
+			for group, add := range groups {
+				reference := &cobra.Command{}
+				add(&analysisFlags{}, reference)
+
+				reference.Flags().VisitAll(func(want *pflag.Flag) {
+					got := c.cmd.Flags().Lookup(want.Name)
+					switch {
+					case !registered[group] && got != nil:
+						t.Errorf("--%s of the %s flags is registered", want.Name, group)
+					case registered[group] && got == nil:
+						t.Errorf("--%s of the %s flags is missing", want.Name, group)
+					case got != nil && (got.Usage != want.Usage || got.DefValue != want.DefValue || got.Shorthand != want.Shorthand):
+						t.Errorf("--%s = %q (default %q), want %q (default %q)", want.Name, got.Usage, got.DefValue, want.Usage, want.DefValue)
+					}
+				})
+			}
+		})
+	}
+}
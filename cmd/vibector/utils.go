@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func detectFormatFromExtension(filePath string) (string, error) {
@@ -20,3 +23,55 @@ func detectFormatFromExtension(filePath string) (string, error) {
 		return "", fmt.Errorf("unsupported file extension: %s", ext)
 	}
 }
+
+// parseDate accepts an absolute date (2006-01-02 or RFC3339) or a relative
+// age such as 36h, 14d or 2w, which is counted back from now.
+func parseDate(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, RFC3339 or a relative age like 14d", value)
+}
+
+// parseUntil is parseDate for upper bounds. A date without a time covers the
+// whole day, so it ends right before the next midnight.
+func parseUntil(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	return parseDate(value, now)
+}
+
+// cacheDir returns dir, or the vibector directory of the user's cache
+// directory ($XDG_CACHE_HOME on Linux) when it is empty.
+func cacheDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory, use --cache-dir or --no-cache: %w", err)
+	}
+
+	return filepath.Join(base, "vibector"), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"date", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"RFC3339", "2024-03-01T10:30:00Z", time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC), false},
+		{"hours", "36h", now.Add(-36 * time.Hour), false},
+		{"days", "14d", now.AddDate(0, 0, -14), false},
+		{"weeks", "2w", now.AddDate(0, 0, -14), false},
+		{"invalid", "yesterday", time.Time{}, true},
+		{"negative", "-3d", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2024, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("date covers the whole day", func(t *testing.T) {
+		until, err := parseUntil("2024-03-31", now)
+		if err != nil {
+			t.Fatalf("parseUntil() unexpected error = %v", err)
+		}
+
+		lastCommit := time.Date(2024, 3, 31, 23, 59, 59, 0, time.Local)
+		if lastCommit.After(until) {
+			t.Errorf("parseUntil() = %v, want it to include %v", until, lastCommit)
+		}
+		nextDay := time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)
+		if !nextDay.After(until) {
+			t.Errorf("parseUntil() = %v, want it to exclude %v", until, nextDay)
+		}
+	})
+
+	t.Run("exact time is kept", func(t *testing.T) {
+		until, err := parseUntil("2024-03-31T10:00:00Z", now)
+		if err != nil {
+			t.Fatalf("parseUntil() unexpected error = %v", err)
+		}
+		if want := time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC); !until.Equal(want) {
+			t.Errorf("parseUntil() = %v, want %v", until, want)
+		}
+	})
+
+	t.Run("relative age is kept", func(t *testing.T) {
+		until, err := parseUntil("14d", now)
+		if err != nil {
+			t.Fatalf("parseUntil() unexpected error = %v", err)
+		}
+		if want := now.AddDate(0, 0, -14); !until.Equal(want) {
+			t.Errorf("parseUntil() = %v, want %v", until, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := parseUntil("2024-02-30", now); err == nil {
+			t.Error("parseUntil() expected error, got nil")
+		}
+	})
+}
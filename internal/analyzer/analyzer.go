@@ -14,6 +14,10 @@ type AnalysisResult struct {
 	Commits      []*git.Commit
 	CommitPairs  []*git.CommitPair
 	TotalCommits int
+
+	// ShallowCommits counts the boundary commits of a shallow clone, whose
+	// changes could not be analyzed because their parents are missing.
+	ShallowCommits int
 }
 
 func New(repo git.Repository) *Analyzer {
@@ -45,10 +49,18 @@ func (a *Analyzer) AnalyzeRepository(opts *git.CommitOptions) (*AnalysisResult,
 		return nil, fmt.Errorf("failed to create commit pairs: %w", err)
 	}
 
+	shallow := 0
+	for _, c := range commits {
+		if c.Shallow {
+			shallow++
+		}
+	}
+
 	return &AnalysisResult{
-		Commits:      commits,
-		CommitPairs:  pairs,
-		TotalCommits: len(commits),
+		Commits:        commits,
+		CommitPairs:    pairs,
+		TotalCommits:   len(commits),
+		ShallowCommits: shallow,
 	}, nil
 }
 
@@ -61,3 +73,77 @@ func (a *Analyzer) createCommitPairs(commits []*git.Commit) ([]*git.CommitPair,
 
 	return nil, fmt.Errorf("repository does not support commit pair creation")
 }
+
+// Tips returns the commits the analysis starts from, keyed by ref name.
+func (a *Analyzer) Tips(opts *git.CommitOptions) (map[string]string, error) {
+	if repo, ok := a.repo.(interface {
+		ResolveTips(*git.CommitOptions) (map[string]string, error)
+	}); ok {
+		return repo.ResolveTips(opts)
+	}
+
+	return nil, fmt.Errorf("repository does not support resolving tips")
+}
+
+// Location returns the git directory or URL that identifies the repository.
+func (a *Analyzer) Location() (string, error) {
+	if repo, ok := a.repo.(interface{ Location() string }); ok {
+		return repo.Location(), nil
+	}
+
+	return "", fmt.Errorf("repository does not support locating its git directory")
+}
+
+// Fingerprint identifies the settings the diff statistics were computed with.
+func (a *Analyzer) Fingerprint() (string, error) {
+	if repo, ok := a.repo.(interface{ Fingerprint() string }); ok {
+		return repo.Fingerprint(), nil
+	}
+
+	return "", fmt.Errorf("repository does not support fingerprinting its settings")
+}
+
+// Pending returns the uncommitted changes paired with HEAD, or nil when the
+// repository has no commits yet.
+func (a *Analyzer) Pending(opts *git.PendingOptions) (*git.CommitPair, error) {
+	if repo, ok := a.repo.(interface {
+		GetPendingChanges(*git.PendingOptions) (*git.CommitPair, error)
+	}); ok {
+		return repo.GetPendingChanges(opts)
+	}
+
+	return nil, fmt.Errorf("repository does not support pending changes")
+}
+
+// Submodules returns the initialized submodules of the repository.
+func (a *Analyzer) Submodules() ([]git.Submodule, error) {
+	if repo, ok := a.repo.(interface {
+		Submodules() ([]git.Submodule, error)
+	}); ok {
+		return repo.Submodules()
+	}
+
+	return nil, fmt.Errorf("repository does not support submodules")
+}
+
+// ReadNotes returns the notes stored under ref keyed by commit hash.
+func (a *Analyzer) ReadNotes(ref string) (map[string]string, error) {
+	if repo, ok := a.repo.(interface {
+		ReadNotes(string) (map[string]string, error)
+	}); ok {
+		return repo.ReadNotes(ref)
+	}
+
+	return nil, fmt.Errorf("repository does not support notes")
+}
+
+// WriteNotes adds or replaces notes under ref.
+func (a *Analyzer) WriteNotes(ref string, notes map[string]string, message string) error {
+	if repo, ok := a.repo.(interface {
+		WriteNotes(string, map[string]string, string) error
+	}); ok {
+		return repo.WriteNotes(ref, notes, message)
+	}
+
+	return fmt.Errorf("repository does not support notes")
+}
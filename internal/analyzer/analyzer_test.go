@@ -268,3 +268,161 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// tipsRepository adds tip resolution to mockRepository
+type tipsRepository struct {
+	mockRepository
+	tips map[string]string
+}
+
+func (m *tipsRepository) ResolveTips(opts *git.CommitOptions) (map[string]string, error) {
+	return m.tips, nil
+}
+
+func TestAnalyzer_Tips(t *testing.T) {
+	t.Run("repository resolving tips", func(t *testing.T) {
+		repo := &tipsRepository{tips: map[string]string{"HEAD": "abc123"}}
+
+		tips, err := New(repo).Tips(nil)
+		if err != nil {
+			t.Fatalf("Tips() unexpected error = %v", err)
+		}
+		if tips["HEAD"] != "abc123" {
+			t.Errorf("Tips() = %v, want HEAD at abc123", tips)
+		}
+	})
+
+	t.Run("repository without tip support", func(t *testing.T) {
+		if _, err := New(&mockRepository{}).Tips(nil); err == nil {
+			t.Error("Tips() expected error, got nil")
+		}
+	})
+}
+
+func TestAnalyzer_AnalyzeRepository_ShallowCommits(t *testing.T) {
+	now := time.Now()
+	commits := []*git.Commit{
+		{Hash: "abc123", Timestamp: now},
+		{Hash: "def456", Timestamp: now.Add(-time.Hour), Shallow: true},
+	}
+
+	repo := &mockRepository{
+		commits:      commits,
+		commitPairs:  []*git.CommitPair{},
+		supportPairs: true,
+	}
+
+	result, err := New(repo).AnalyzeRepository(nil)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository() unexpected error = %v", err)
+	}
+	if result.ShallowCommits != 1 {
+		t.Errorf("ShallowCommits = %d, want 1", result.ShallowCommits)
+	}
+}
+
+// pendingRepository adds pending changes to mockRepository
+type pendingRepository struct {
+	mockRepository
+	pair *git.CommitPair
+}
+
+func (m *pendingRepository) GetPendingChanges(opts *git.PendingOptions) (*git.CommitPair, error) {
+	return m.pair, nil
+}
+
+func TestAnalyzer_Pending(t *testing.T) {
+	t.Run("repository with pending changes", func(t *testing.T) {
+		pair := &git.CommitPair{Stats: &git.DiffStats{Additions: 10}}
+
+		got, err := New(&pendingRepository{pair: pair}).Pending(nil)
+		if err != nil {
+			t.Fatalf("Pending() unexpected error = %v", err)
+		}
+		if got != pair {
+			t.Errorf("Pending() = %+v, want %+v", got, pair)
+		}
+	})
+
+	t.Run("repository without pending support", func(t *testing.T) {
+		if _, err := New(&mockRepository{}).Pending(nil); err == nil {
+			t.Error("Pending() expected error, got nil")
+		}
+	})
+}
+
+// submodulesRepository adds submodule listing to mockRepository
+type submodulesRepository struct {
+	mockRepository
+	submodules []git.Submodule
+}
+
+func (m *submodulesRepository) Submodules() ([]git.Submodule, error) {
+	return m.submodules, nil
+}
+
+func TestAnalyzer_Submodules(t *testing.T) {
+	t.Run("repository with submodules", func(t *testing.T) {
+		repo := &submodulesRepository{submodules: []git.Submodule{{Name: "lib", Path: "vendor/lib"}}}
+
+		submodules, err := New(repo).Submodules()
+		if err != nil {
+			t.Fatalf("Submodules() unexpected error = %v", err)
+		}
+		if len(submodules) != 1 || submodules[0].Path != "vendor/lib" {
+			t.Errorf("Submodules() = %+v, want vendor/lib", submodules)
+		}
+	})
+
+	t.Run("repository without submodule support", func(t *testing.T) {
+		if _, err := New(&mockRepository{}).Submodules(); err == nil {
+			t.Error("Submodules() expected error, got nil")
+		}
+	})
+}
+
+// notesRepository keeps notes in memory on top of mockRepository
+type notesRepository struct {
+	mockRepository
+	notes map[string]string
+}
+
+func (m *notesRepository) ReadNotes(ref string) (map[string]string, error) {
+	return m.notes, nil
+}
+
+func (m *notesRepository) WriteNotes(ref string, notes map[string]string, message string) error {
+	for hash, note := range notes {
+		m.notes[hash] = note
+	}
+	return nil
+}
+
+func TestAnalyzer_Notes(t *testing.T) {
+	t.Run("repository with notes", func(t *testing.T) {
+		repo := &notesRepository{notes: map[string]string{"abc": "old"}}
+		a := New(repo)
+
+		if err := a.WriteNotes(git.DefaultNotesRef, map[string]string{"def": "new"}, "annotate"); err != nil {
+			t.Fatalf("WriteNotes() unexpected error = %v", err)
+		}
+
+		notes, err := a.ReadNotes(git.DefaultNotesRef)
+		if err != nil {
+			t.Fatalf("ReadNotes() unexpected error = %v", err)
+		}
+		if len(notes) != 2 || notes["abc"] != "old" || notes["def"] != "new" {
+			t.Errorf("ReadNotes() = %v, want both notes", notes)
+		}
+	})
+
+	t.Run("repository without notes support", func(t *testing.T) {
+		a := New(&mockRepository{})
+		if _, err := a.ReadNotes(git.DefaultNotesRef); err == nil {
+			t.Error("ReadNotes() expected error, got nil")
+		}
+		if err := a.WriteNotes(git.DefaultNotesRef, map[string]string{"abc": "note"}, "annotate"); err == nil {
+			t.Error("WriteNotes() expected error, got nil")
+		}
+	})
+}
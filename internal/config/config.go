@@ -12,6 +12,18 @@ import (
 type Config struct {
 	Thresholds   detector.Thresholds
 	ExcludeFiles []string
+
+	// Languages maps file extensions to the language they are counted as,
+	// overriding the built-in classification.
+	Languages map[string]string
+
+	// MailmapFile is applied after the repository's .mailmap to normalize
+	// author identities.
+	MailmapFile string
+
+	// KeyringFile holds the OpenPGP keys and SSH allowed signers commit
+	// signatures are verified against.
+	KeyringFile string
 }
 
 func Load(configFile string) (*Config, error) {
@@ -34,8 +46,37 @@ func Load(configFile string) (*Config, error) {
 	config.Thresholds.MaxAdditionsPerMin = v.GetFloat64("thresholds.max_additions_per_min")
 	config.Thresholds.MaxDeletionsPerMin = v.GetFloat64("thresholds.max_deletions_per_min")
 	config.Thresholds.MinTimeDeltaSeconds = v.GetInt64("thresholds.min_time_delta_seconds")
+	config.Thresholds.MaxTimestampSkewSeconds = v.GetInt64("thresholds.max_timestamp_skew_seconds")
+
+	for name := range v.GetStringMap("thresholds.languages") {
+		if config.Thresholds.Languages == nil {
+			config.Thresholds.Languages = make(map[string]detector.LanguageThresholds)
+		}
+		key := "thresholds.languages." + name
+		config.Thresholds.Languages[name] = detector.LanguageThresholds{
+			SuspiciousAdditions: v.GetInt64(key + ".suspicious_additions"),
+			SuspiciousDeletions: v.GetInt64(key + ".suspicious_deletions"),
+			MaxAdditionsPerMin:  v.GetFloat64(key + ".max_additions_per_min"),
+			MaxDeletionsPerMin:  v.GetFloat64(key + ".max_deletions_per_min"),
+		}
+	}
+
+	if v.IsSet("ai_signatures") {
+		config.Thresholds.AISignatures = append([]string{}, v.GetStringSlice("ai_signatures")...)
+	}
 
 	config.ExcludeFiles = v.GetStringSlice("exclude_files")
+	config.MailmapFile = v.GetString("mailmap_file")
+	config.KeyringFile = v.GetString("keyring_file")
+
+	for name, extensions := range v.GetStringMapStringSlice("languages") {
+		if config.Languages == nil {
+			config.Languages = make(map[string]string)
+		}
+		for _, ext := range extensions {
+			config.Languages[ext] = name
+		}
+	}
 
 	return config, nil
 }
@@ -59,8 +100,40 @@ thresholds:
   # Time threshold - flag commits that are too close together
   min_time_delta_seconds: 60   # Flag commits less than 60 seconds apart (0 to disable)
 
-# File patterns to exclude from diff statistics (e.g., ["*.log", "*.tmp", "package-lock.json"])
+  # History rewrite threshold - flag commits whose author and committer times diverge
+  # Rebased or cherry-picked commits keep their author time but get a new committer time
+  max_timestamp_skew_seconds: 0   # Flag if author/committer times differ by more than this (0 to disable)
+
+  # Per-language thresholds, checked against the lines of that language only
+  # and on top of the thresholds above. Language names are case-insensitive.
+  # languages:
+  #   go:
+  #     suspicious_additions: 300
+  #   typescript:
+  #     max_additions_per_min: 60
+
+# Gitignore-style patterns to exclude from diff statistics (e.g., ["*.log", "vendor/**", "!important.lock"])
+# Patterns from the repository's .vibectorignore are applied first
 exclude_files: []
+
+# Regular expressions matched case-insensitively against commit trailers and
+# message lines. A match flags the commit as AI-assisted regardless of its size.
+# Leave unset to use the built-in signatures (Co-authored-by and "Generated with"
+# lines of common AI tools), or set to [] to disable the check.
+# ai_signatures:
+#   - '^co-authored-by:.*<[0-9]+\+copilot@users\.noreply\.github\.com>'
+
+# Extra mailmap applied after the repository's .mailmap, e.g. to merge the
+# work and personal emails of one developer (git's .mailmap format)
+# mailmap_file: ""
+
+# Armored OpenPGP public keys and/or SSH allowed signers ("principals key-type key")
+# to verify commit signatures against. Without it signatures are reported unverified
+# keyring_file: ""
+
+# Extra file extensions per language, overriding the built-in classification
+# languages:
+#   go: [".tmpl"]
 `
 
 	return os.WriteFile(path, []byte(sample), 0o600)
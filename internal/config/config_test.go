@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -30,9 +31,11 @@ func TestLoad(t *testing.T) {
   max_additions_per_min: 100.5
   max_deletions_per_min: 200.5
   min_time_delta_seconds: 60
+  max_timestamp_skew_seconds: 3600
 exclude_files:
   - "*.log"
   - "*.tmp"
+mailmap_file: team.mailmap
 `
 		if err := os.WriteFile(configFile, []byte(yamlContent), 0o600); err != nil {
 			t.Fatalf("Failed to write test config file: %v", err)
@@ -58,6 +61,9 @@ exclude_files:
 		if config.Thresholds.MinTimeDeltaSeconds != 60 {
 			t.Errorf("MinTimeDeltaSeconds = %d, want 60", config.Thresholds.MinTimeDeltaSeconds)
 		}
+		if config.Thresholds.MaxTimestampSkewSeconds != 3600 {
+			t.Errorf("MaxTimestampSkewSeconds = %d, want 3600", config.Thresholds.MaxTimestampSkewSeconds)
+		}
 		if len(config.ExcludeFiles) != 2 {
 			t.Errorf("len(ExcludeFiles) = %d, want 2", len(config.ExcludeFiles))
 		}
@@ -67,6 +73,9 @@ exclude_files:
 		if len(config.ExcludeFiles) >= 2 && config.ExcludeFiles[1] != "*.tmp" {
 			t.Errorf("ExcludeFiles[1] = %s, want *.tmp", config.ExcludeFiles[1])
 		}
+		if config.MailmapFile != "team.mailmap" {
+			t.Errorf("MailmapFile = %s, want team.mailmap", config.MailmapFile)
+		}
 	})
 
 	t.Run("load from json file", func(t *testing.T) {
@@ -166,6 +175,72 @@ exclude_files:
 			t.Errorf("Unset SuspiciousDeletions should be 0, got %d", config.Thresholds.SuspiciousDeletions)
 		}
 	})
+
+	t.Run("ai signatures", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			want    []string
+		}{
+			{"unset uses defaults", "thresholds:\n  suspicious_additions: 1\n", nil},
+			{"custom list", "ai_signatures:\n  - '^x-tool:'\n", []string{"^x-tool:"}},
+			{"empty list disables", "ai_signatures: []\n", []string{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				configFile := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(configFile, []byte(tt.content), 0o600); err != nil {
+					t.Fatalf("Failed to write test config file: %v", err)
+				}
+
+				config, err := Load(configFile)
+				if err != nil {
+					t.Fatalf("Load() unexpected error = %v", err)
+				}
+				if !reflect.DeepEqual(config.Thresholds.AISignatures, tt.want) {
+					t.Errorf("AISignatures = %#v, want %#v", config.Thresholds.AISignatures, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("language thresholds and overrides", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "languages.yaml")
+
+		content := `thresholds:
+  suspicious_additions: 500
+  languages:
+    Go:
+      suspicious_additions: 200
+    typescript:
+      max_additions_per_min: 40.5
+languages:
+  go: [".tmpl", ".gotmpl"]
+`
+		if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+
+		config, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() unexpected error = %v", err)
+		}
+
+		if len(config.Thresholds.Languages) != 2 {
+			t.Fatalf("len(Thresholds.Languages) = %d, want 2", len(config.Thresholds.Languages))
+		}
+		if got := config.Thresholds.Languages["go"].SuspiciousAdditions; got != 200 {
+			t.Errorf("go SuspiciousAdditions = %d, want 200", got)
+		}
+		if got := config.Thresholds.Languages["typescript"].MaxAdditionsPerMin; got != 40.5 {
+			t.Errorf("typescript MaxAdditionsPerMin = %f, want 40.5", got)
+		}
+		if config.Languages[".tmpl"] != "go" || config.Languages[".gotmpl"] != "go" {
+			t.Errorf("Languages = %v, want .tmpl and .gotmpl mapped to go", config.Languages)
+		}
+	})
 }
 
 func TestGenerateSampleConfig(t *testing.T) {
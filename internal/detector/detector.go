@@ -2,6 +2,8 @@ package detector
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/anisimov-anthony/vibector/internal/git"
@@ -13,10 +15,21 @@ type SuspiciousCommit struct {
 	AdditionVelocity *metrics.VelocityMetrics
 	DeletionVelocity *metrics.VelocityMetrics
 	Reasons          []string
+
+	// AISignature is the trailer or message line that matched an AI tool
+	// signature, if any.
+	AISignature string
+}
+
+// Score is the number of checks the commit failed. It ranks commits; it is
+// not a probability.
+func (s *SuspiciousCommit) Score() int {
+	return len(s.Reasons)
 }
 
 type Detector struct {
 	thresholds *Thresholds
+	signatures []*regexp.Regexp
 }
 
 func New(thresholds *Thresholds) (*Detector, error) {
@@ -24,99 +37,282 @@ func New(thresholds *Thresholds) (*Detector, error) {
 		return nil, fmt.Errorf("invalid thresholds: %w", err)
 	}
 
+	signatures, err := compileSignatures(thresholds.Signatures())
+	if err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
 	return &Detector{
 		thresholds: thresholds,
+		signatures: signatures,
 	}, nil
 }
 
-func (d *Detector) DetectSuspicious(pairs []*git.CommitPair, repoStats *metrics.RepositoryStats) []*SuspiciousCommit {
-	if pairs == nil {
-		return []*SuspiciousCommit{}
+// DetectSuspicious checks every pair against the thresholds and every commit
+// against the AI signatures. Signatures are checked whether a commit was
+// paired or not, so root, shallow-boundary and merge commits, and commits
+// sharing the timestamp of their parent, are covered too. Without commits the
+// current commits of the pairs are checked.
+func (d *Detector) DetectSuspicious(commits []*git.Commit, pairs []*git.CommitPair, repoStats *metrics.RepositoryStats) []*SuspiciousCommit {
+	paired := make(map[string]*git.CommitPair, len(pairs))
+	for _, pair := range pairs {
+		paired[pair.Current.Hash] = pair
+	}
+	if commits == nil {
+		commits = make([]*git.Commit, 0, len(pairs))
+		for _, pair := range pairs {
+			commits = append(commits, pair.Current)
+		}
 	}
 
 	suspicious := make([]*SuspiciousCommit, 0)
 
-	for _, pair := range pairs {
-		if pair.Stats.Additions == 0 && pair.Stats.Deletions == 0 {
-			continue
-		}
+	for _, c := range commits {
+		pair := paired[c.Hash]
 
-		reasons := make([]string, 0)
+		var s *SuspiciousCommit
+		switch {
+		case pair == nil:
+		case pair.IsMerge:
+			s = d.detectMerge(pair)
+		default:
+			s = d.detectPair(pair)
+		}
 
-		var additionVelocity, deletionVelocity *metrics.VelocityMetrics
-		if d.thresholds.MaxAdditionsPerMin > 0 || d.thresholds.MaxDeletionsPerMin > 0 {
-			var err error
-			additionVelocity, err = metrics.CalculateVelocity(pair.Stats.Additions, pair.TimeDelta)
-			if err != nil {
-				continue
-			}
-			deletionVelocity, err = metrics.CalculateVelocity(pair.Stats.Deletions, pair.TimeDelta)
-			if err != nil {
-				continue
+		if signature, ok := matchSignature(c, d.signatures); ok {
+			if s == nil {
+				if pair == nil {
+					pair = &git.CommitPair{Current: c, Stats: &git.DiffStats{}}
+				}
+				s = &SuspiciousCommit{Pair: pair}
 			}
+			reason := fmt.Sprintf("AI tool signature in commit message (high confidence): %q", signature)
+			s.Reasons = append([]string{reason}, s.Reasons...)
+			s.AISignature = signature
 		}
 
-		if d.thresholds.MinTimeDeltaSeconds > 0 {
-			if pair.TimeDelta.Seconds() < float64(d.thresholds.MinTimeDeltaSeconds) {
-				reasons = append(reasons, fmt.Sprintf(
-					"Time between commits too short: %.1f seconds (threshold: %d seconds)",
-					pair.TimeDelta.Seconds(),
-					d.thresholds.MinTimeDeltaSeconds,
-				))
-			}
+		if s != nil {
+			suspicious = append(suspicious, s)
 		}
+	}
 
-		if d.thresholds.SuspiciousAdditions > 0 {
-			if pair.Stats.Additions > d.thresholds.SuspiciousAdditions {
-				reasons = append(reasons, fmt.Sprintf(
-					"Suspicious commit size: %d additions (threshold: %d lines)",
-					pair.Stats.Additions,
-					d.thresholds.SuspiciousAdditions,
-				))
-			}
+	return suspicious
+}
+
+// detectPair checks a non-merge pair against the size, velocity and timing
+// thresholds.
+func (d *Detector) detectPair(pair *git.CommitPair) *SuspiciousCommit {
+	if pair.Stats.Additions == 0 && pair.Stats.Deletions == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0)
+
+	var additionVelocity, deletionVelocity *metrics.VelocityMetrics
+	if d.thresholds.MaxAdditionsPerMin > 0 || d.thresholds.MaxDeletionsPerMin > 0 {
+		// Without a positive time delta there is no velocity to check,
+		// but the other thresholds still apply.
+		additionVelocity, _ = metrics.CalculateVelocity(pair.Stats.Additions, pair.TimeDelta)
+		deletionVelocity, _ = metrics.CalculateVelocity(pair.Stats.Deletions, pair.TimeDelta)
+	}
+
+	if d.thresholds.MinTimeDeltaSeconds > 0 {
+		if pair.TimeDelta.Seconds() < float64(d.thresholds.MinTimeDeltaSeconds) {
+			reasons = append(reasons, fmt.Sprintf(
+				"Time between commits too short: %.1f seconds (threshold: %d seconds)",
+				pair.TimeDelta.Seconds(),
+				d.thresholds.MinTimeDeltaSeconds,
+			))
 		}
+	}
 
-		if d.thresholds.SuspiciousDeletions > 0 {
-			if pair.Stats.Deletions > d.thresholds.SuspiciousDeletions {
-				reasons = append(reasons, fmt.Sprintf(
-					"Suspicious commit size: %d deletions (threshold: %d lines)",
-					pair.Stats.Deletions,
-					d.thresholds.SuspiciousDeletions,
-				))
-			}
+	if d.thresholds.MaxTimestampSkewSeconds > 0 {
+		if skew, ok := timestampSkew(pair.Current); ok && skew.Seconds() > float64(d.thresholds.MaxTimestampSkewSeconds) {
+			reasons = append(reasons, fmt.Sprintf(
+				"Author/committer time skew too large: %.0f seconds (threshold: %d seconds), history may have been rewritten",
+				skew.Seconds(),
+				d.thresholds.MaxTimestampSkewSeconds,
+			))
+		}
+	}
+
+	if d.thresholds.SuspiciousAdditions > 0 {
+		if pair.Stats.Additions > d.thresholds.SuspiciousAdditions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Suspicious commit size: %d additions (threshold: %d lines)",
+				pair.Stats.Additions,
+				d.thresholds.SuspiciousAdditions,
+			))
+		}
+	}
+
+	if d.thresholds.SuspiciousDeletions > 0 {
+		if pair.Stats.Deletions > d.thresholds.SuspiciousDeletions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Suspicious commit size: %d deletions (threshold: %d lines)",
+				pair.Stats.Deletions,
+				d.thresholds.SuspiciousDeletions,
+			))
+		}
+	}
+
+	if d.thresholds.MaxAdditionsPerMin > 0 && additionVelocity != nil {
+		if additionVelocity.LOCPerMinute > d.thresholds.MaxAdditionsPerMin {
+			reasons = append(reasons, fmt.Sprintf(
+				"Addition velocity too high: %.1f additions/min (threshold: %.1f additions/min)",
+				additionVelocity.LOCPerMinute,
+				d.thresholds.MaxAdditionsPerMin,
+			))
+		}
+	}
+
+	if d.thresholds.MaxDeletionsPerMin > 0 && deletionVelocity != nil {
+		if deletionVelocity.LOCPerMinute > d.thresholds.MaxDeletionsPerMin {
+			reasons = append(reasons, fmt.Sprintf(
+				"Deletion velocity too high: %.1f deletions/min (threshold: %.1f deletions/min)",
+				deletionVelocity.LOCPerMinute,
+				d.thresholds.MaxDeletionsPerMin,
+			))
+		}
+	}
+
+	reasons = append(reasons, d.languageReasons(pair)...)
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return &SuspiciousCommit{
+		Pair:             pair,
+		AdditionVelocity: additionVelocity,
+		DeletionVelocity: deletionVelocity,
+		Reasons:          reasons,
+	}
+}
+
+// FilterUnsigned keeps the suspicious commits that are unsigned or whose
+// signature did not verify against the keyring. Signatures that could not be
+// checked, because there is no keyring or the format is not supported, are
+// not trusted either.
+func FilterUnsigned(suspicious []*SuspiciousCommit) []*SuspiciousCommit {
+	unsigned := make([]*SuspiciousCommit, 0, len(suspicious))
+	for _, s := range suspicious {
+		sig := s.Pair.Current.Signature
+		if sig == nil || sig.Status != git.SignatureGood {
+			unsigned = append(unsigned, s)
 		}
+	}
+
+	return unsigned
+}
 
-		if d.thresholds.MaxAdditionsPerMin > 0 && additionVelocity != nil {
-			if additionVelocity.LOCPerMinute > d.thresholds.MaxAdditionsPerMin {
+// languageReasons checks the lines of each language against the thresholds
+// configured for it.
+func (d *Detector) languageReasons(pair *git.CommitPair) []string {
+	if len(d.thresholds.Languages) == 0 || len(pair.Stats.Languages) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(pair.Stats.Languages))
+	for name := range pair.Stats.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var reasons []string
+	for _, name := range names {
+		limits, ok := d.thresholds.language(name)
+		if !ok {
+			continue
+		}
+		lang := pair.Stats.Languages[name]
+
+		if limits.SuspiciousAdditions > 0 && lang.Additions > limits.SuspiciousAdditions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Suspicious %s commit size: %d additions (threshold: %d lines)",
+				name, lang.Additions, limits.SuspiciousAdditions,
+			))
+		}
+		if limits.SuspiciousDeletions > 0 && lang.Deletions > limits.SuspiciousDeletions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Suspicious %s commit size: %d deletions (threshold: %d lines)",
+				name, lang.Deletions, limits.SuspiciousDeletions,
+			))
+		}
+
+		if limits.MaxAdditionsPerMin > 0 {
+			if velocity, err := metrics.CalculateVelocityPerMinute(lang.Additions, pair.TimeDelta); err == nil && velocity > limits.MaxAdditionsPerMin {
 				reasons = append(reasons, fmt.Sprintf(
-					"Addition velocity too high: %.1f additions/min (threshold: %.1f additions/min)",
-					additionVelocity.LOCPerMinute,
-					d.thresholds.MaxAdditionsPerMin,
+					"%s addition velocity too high: %.1f additions/min (threshold: %.1f additions/min)",
+					name, velocity, limits.MaxAdditionsPerMin,
 				))
 			}
 		}
-
-		if d.thresholds.MaxDeletionsPerMin > 0 && deletionVelocity != nil {
-			if deletionVelocity.LOCPerMinute > d.thresholds.MaxDeletionsPerMin {
+		if limits.MaxDeletionsPerMin > 0 {
+			if velocity, err := metrics.CalculateVelocityPerMinute(lang.Deletions, pair.TimeDelta); err == nil && velocity > limits.MaxDeletionsPerMin {
 				reasons = append(reasons, fmt.Sprintf(
-					"Deletion velocity too high: %.1f deletions/min (threshold: %.1f deletions/min)",
-					deletionVelocity.LOCPerMinute,
-					d.thresholds.MaxDeletionsPerMin,
+					"%s deletion velocity too high: %.1f deletions/min (threshold: %.1f deletions/min)",
+					name, velocity, limits.MaxDeletionsPerMin,
 				))
 			}
 		}
+	}
+
+	return reasons
+}
 
-		if len(reasons) > 0 {
-			suspicious = append(suspicious, &SuspiciousCommit{
-				Pair:             pair,
-				AdditionVelocity: additionVelocity,
-				DeletionVelocity: deletionVelocity,
-				Reasons:          reasons,
-			})
+// detectMerge only judges what the merge itself introduced; the first-parent
+// diff of a merge repeats the merged branch's commits, which are paired on their own.
+func (d *Detector) detectMerge(pair *git.CommitPair) *SuspiciousCommit {
+	if pair.MergeStats == nil {
+		return nil
+	}
+
+	reasons := make([]string, 0)
+
+	if d.thresholds.SuspiciousAdditions > 0 {
+		if pair.MergeStats.Additions > d.thresholds.SuspiciousAdditions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Merge introduces %d additions not present in any parent (threshold: %d lines)",
+				pair.MergeStats.Additions,
+				d.thresholds.SuspiciousAdditions,
+			))
 		}
 	}
 
-	return suspicious
+	if d.thresholds.SuspiciousDeletions > 0 {
+		if pair.MergeStats.Deletions > d.thresholds.SuspiciousDeletions {
+			reasons = append(reasons, fmt.Sprintf(
+				"Merge drops %d lines present in every parent (threshold: %d lines)",
+				pair.MergeStats.Deletions,
+				d.thresholds.SuspiciousDeletions,
+			))
+		}
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return &SuspiciousCommit{
+		Pair:    pair,
+		Reasons: reasons,
+	}
+}
+
+// timestampSkew reports how far the author and committer clocks of a commit
+// diverge. Rebases and cherry-picks keep the author time but reset the
+// committer time, so a large skew hints at rewritten history.
+func timestampSkew(c *git.Commit) (time.Duration, bool) {
+	if c.AuthorTimestamp.IsZero() || c.CommitterTimestamp.IsZero() {
+		return 0, false
+	}
+
+	skew := c.CommitterTimestamp.Sub(c.AuthorTimestamp)
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew, true
 }
 
 func FormatTimeDelta(d time.Duration) string {
@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -62,7 +63,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 
 	t.Run("nil pairs returns empty", func(t *testing.T) {
 		d, _ := New(&Thresholds{SuspiciousAdditions: 100})
-		result := d.DetectSuspicious(nil, nil)
+		result := d.DetectSuspicious(nil, nil, nil)
 		if len(result) != 0 {
 			t.Errorf("DetectSuspicious(nil) returned %d results, want 0", len(result))
 		}
@@ -70,7 +71,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 
 	t.Run("empty pairs returns empty", func(t *testing.T) {
 		d, _ := New(&Thresholds{SuspiciousAdditions: 100})
-		result := d.DetectSuspicious([]*git.CommitPair{}, nil)
+		result := d.DetectSuspicious(nil, []*git.CommitPair{}, nil)
 		if len(result) != 0 {
 			t.Errorf("DetectSuspicious([]) returned %d results, want 0", len(result))
 		}
@@ -95,7 +96,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -121,7 +122,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -144,7 +145,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -173,7 +174,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -202,7 +203,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -229,7 +230,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 1 {
 			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
 		}
@@ -255,7 +256,7 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 0 {
 			t.Errorf("DetectSuspicious() returned %d results, want 0 (no changes)", len(result))
 		}
@@ -284,11 +285,187 @@ func TestDetector_DetectSuspicious(t *testing.T) {
 			},
 		}
 
-		result := d.DetectSuspicious(pairs, nil)
+		result := d.DetectSuspicious(nil, pairs, nil)
 		if len(result) != 0 {
 			t.Errorf("DetectSuspicious() returned %d results, want 0", len(result))
 		}
 	})
+
+	t.Run("judges merges by introduced lines only", func(t *testing.T) {
+		d, _ := New(&Thresholds{
+			SuspiciousAdditions: 100,
+			MaxAdditionsPerMin:  10.0,
+		})
+		pairs := []*git.CommitPair{
+			{
+				Previous:  &git.Commit{Hash: "abc123"},
+				Current:   &git.Commit{Hash: "merge1", Timestamp: now, Parents: []string{"abc123", "feat1"}},
+				TimeDelta: time.Minute,
+				Stats:     &git.DiffStats{Additions: 5000},
+				IsMerge:   true,
+				MergeStats: &git.DiffStats{
+					Additions: 10,
+				},
+			},
+			{
+				Previous:  &git.Commit{Hash: "abc123"},
+				Current:   &git.Commit{Hash: "merge2", Timestamp: now, Parents: []string{"abc123", "feat2"}},
+				TimeDelta: time.Minute,
+				Stats:     &git.DiffStats{Additions: 5000},
+				IsMerge:   true,
+				MergeStats: &git.DiffStats{
+					Additions: 300,
+				},
+			},
+		}
+
+		result := d.DetectSuspicious(nil, pairs, nil)
+		if len(result) != 1 {
+			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
+		}
+		if result[0].Pair.Current.Hash != "merge2" {
+			t.Errorf("flagged %s, want merge2", result[0].Pair.Current.Hash)
+		}
+		if len(result[0].Reasons) != 1 || !strings.Contains(result[0].Reasons[0], "Merge introduces 300 additions") {
+			t.Errorf("Reasons = %v, want merge-introduced additions reason", result[0].Reasons)
+		}
+	})
+
+	t.Run("detects author/committer timestamp skew", func(t *testing.T) {
+		d, _ := New(&Thresholds{MaxTimestampSkewSeconds: 3600})
+		pairs := []*git.CommitPair{
+			{
+				Previous: &git.Commit{Hash: "abc123"},
+				Current: &git.Commit{
+					Hash:               "rebased",
+					Timestamp:          now,
+					AuthorTimestamp:    now.Add(-48 * time.Hour),
+					CommitterTimestamp: now,
+				},
+				TimeDelta: 10 * time.Minute,
+				Stats:     &git.DiffStats{Additions: 10},
+			},
+			{
+				Previous: &git.Commit{Hash: "abc123"},
+				Current: &git.Commit{
+					Hash:               "amended",
+					Timestamp:          now,
+					AuthorTimestamp:    now.Add(-5 * time.Minute),
+					CommitterTimestamp: now,
+				},
+				TimeDelta: 10 * time.Minute,
+				Stats:     &git.DiffStats{Additions: 10},
+			},
+			{
+				Previous:  &git.Commit{Hash: "abc123"},
+				Current:   &git.Commit{Hash: "unknown", Timestamp: now},
+				TimeDelta: 10 * time.Minute,
+				Stats:     &git.DiffStats{Additions: 10},
+			},
+		}
+
+		result := d.DetectSuspicious(nil, pairs, nil)
+		if len(result) != 1 {
+			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
+		}
+		if result[0].Pair.Current.Hash != "rebased" {
+			t.Errorf("flagged %s, want rebased", result[0].Pair.Current.Hash)
+		}
+		if !contains(result[0].Reasons[0], "time skew too large: 172800 seconds") {
+			t.Errorf("Reasons = %v, want skew reason", result[0].Reasons)
+		}
+	})
+
+	t.Run("applies per-language thresholds", func(t *testing.T) {
+		d, _ := New(&Thresholds{
+			SuspiciousAdditions: 1000,
+			Languages: map[string]LanguageThresholds{
+				"go":         {SuspiciousAdditions: 200},
+				"TypeScript": {MaxAdditionsPerMin: 20},
+			},
+		})
+		pairs := []*git.CommitPair{
+			{
+				Previous:  &git.Commit{Hash: "abc123"},
+				Current:   &git.Commit{Hash: "docs", Timestamp: now},
+				TimeDelta: 10 * time.Minute,
+				Stats: &git.DiffStats{Additions: 600, Languages: map[string]git.LanguageStats{
+					"Markdown": {Additions: 500},
+					"Go":       {Additions: 100},
+				}},
+			},
+			{
+				Previous:  &git.Commit{Hash: "abc123"},
+				Current:   &git.Commit{Hash: "code", Timestamp: now},
+				TimeDelta: 10 * time.Minute,
+				Stats: &git.DiffStats{Additions: 600, Languages: map[string]git.LanguageStats{
+					"Go":         {Additions: 300},
+					"TypeScript": {Additions: 300},
+				}},
+			},
+		}
+
+		result := d.DetectSuspicious(nil, pairs, nil)
+		if len(result) != 1 {
+			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
+		}
+		if result[0].Pair.Current.Hash != "code" {
+			t.Errorf("flagged %s, want code", result[0].Pair.Current.Hash)
+		}
+		wantReasons := []string{
+			"Suspicious Go commit size: 300 additions (threshold: 200 lines)",
+			"TypeScript addition velocity too high: 30.0 additions/min (threshold: 20.0 additions/min)",
+		}
+		if len(result[0].Reasons) != len(wantReasons) {
+			t.Fatalf("Reasons = %v, want %v", result[0].Reasons, wantReasons)
+		}
+		for i, want := range wantReasons {
+			if result[0].Reasons[i] != want {
+				t.Errorf("Reasons[%d] = %q, want %q", i, result[0].Reasons[i], want)
+			}
+		}
+	})
+}
+
+func TestDetector_DetectSuspicious_NoTimeDelta(t *testing.T) {
+	d, _ := New(&Thresholds{SuspiciousAdditions: 100, MaxAdditionsPerMin: 10})
+	pair := &git.CommitPair{
+		Previous: &git.Commit{Hash: "abc123"},
+		Current:  &git.Commit{Hash: "def456", Message: "Add parser\n\nGenerated with Aider\n"},
+		Stats:    &git.DiffStats{Additions: 500},
+	}
+
+	result := d.DetectSuspicious(nil, []*git.CommitPair{pair}, nil)
+	if len(result) != 1 || len(result[0].Reasons) != 2 {
+		t.Fatalf("DetectSuspicious() = %v, want the signature and size reasons", result)
+	}
+	if result[0].AdditionVelocity != nil {
+		t.Errorf("AdditionVelocity = %+v, want nil without a time delta", result[0].AdditionVelocity)
+	}
+}
+
+func TestFilterUnsigned(t *testing.T) {
+	commit := func(hash string, sig *git.Signature) *SuspiciousCommit {
+		return &SuspiciousCommit{Pair: &git.CommitPair{Current: &git.Commit{Hash: hash, Signature: sig}}}
+	}
+	suspicious := []*SuspiciousCommit{
+		commit("unsigned", nil),
+		commit("good", &git.Signature{Format: git.SignatureOpenPGP, Status: git.SignatureGood}),
+		commit("untrusted", &git.Signature{Format: git.SignatureSSH, Status: git.SignatureUntrusted}),
+		commit("unchecked", &git.Signature{Format: git.SignatureSSH, Status: git.SignatureUnchecked}),
+	}
+
+	result := FilterUnsigned(suspicious)
+
+	want := []string{"unsigned", "untrusted", "unchecked"}
+	if len(result) != len(want) {
+		t.Fatalf("FilterUnsigned() returned %d commits, want %d", len(result), len(want))
+	}
+	for i, hash := range want {
+		if result[i].Pair.Current.Hash != hash {
+			t.Errorf("result[%d] = %s, want %s", i, result[i].Pair.Current.Hash, hash)
+		}
+	}
 }
 
 func TestFormatTimeDelta(t *testing.T) {
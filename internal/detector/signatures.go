@@ -0,0 +1,72 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/anisimov-anthony/vibector/internal/git"
+)
+
+// aiTools names the assistants whose "Generated with ..." lines are
+// recognized by the default signatures.
+const aiTools = `(copilot|claude|chatgpt|openai|gpt-?[0-9]|gemini|cursor|devin|aider|codeium|windsurf|tabnine|codex|amazon q)`
+
+// aiCoAuthors are the addresses and display names AI tools use in
+// Co-authored-by trailers. Only bot identities are listed, never a first
+// name such as Claude or Devin, which humans share.
+const (
+	aiCoAuthorEmails = `<(noreply@anthropic\.com|noreply@aider\.chat|cursoragent@cursor\.com|([0-9]+\+)?copilot@users\.noreply\.github\.com|([0-9]+\+)?(copilot-swe-agent|devin-ai-integration|chatgpt-codex-connector|gemini-code-assist)\[bot\]@users\.noreply\.github\.com)>`
+	aiCoAuthorNames  = `(copilot|github copilot|claude code|cursor agent|devin ai|chatgpt|openai codex|gemini code assist|aider( \([^)]*\))?)`
+)
+
+// DefaultAISignatures are used when Thresholds.AISignatures is nil. Each is a
+// regular expression matched case-insensitively against every commit trailer
+// ("Key: value") and every line of the commit message.
+var DefaultAISignatures = []string{
+	`^co-authored-by:.*` + aiCoAuthorEmails,
+	`^co-authored-by:\s*` + aiCoAuthorNames + `\s*(<|$)`,
+	`^(assisted-by|generated-by|ai-assisted-by|ai-generated-by):`,
+	`\bgenerated (with|by|using) \[?` + aiTools + `\b`,
+}
+
+func compileSignatures(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI signature %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// matchSignature returns the first trailer or message line of c that matches
+// one of the signatures.
+func matchSignature(c *git.Commit, signatures []*regexp.Regexp) (string, bool) {
+	if len(signatures) == 0 {
+		return "", false
+	}
+
+	candidates := make([]string, 0, len(c.Trailers))
+	for _, trailer := range c.Trailers {
+		candidates = append(candidates, trailer.String())
+	}
+	for _, line := range strings.Split(c.Message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			candidates = append(candidates, line)
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, re := range signatures {
+			if re.MatchString(candidate) {
+				return candidate, true
+			}
+		}
+	}
+
+	return "", false
+}
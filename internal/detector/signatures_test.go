@@ -0,0 +1,180 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anisimov-anthony/vibector/internal/git"
+)
+
+func TestMatchSignature(t *testing.T) {
+	defaults, err := compileSignatures(DefaultAISignatures)
+	if err != nil {
+		t.Fatalf("compileSignatures() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		commit    *git.Commit
+		want      string
+		wantMatch bool
+	}{
+		{
+			name: "co-authored-by trailer",
+			commit: &git.Commit{
+				Message:  "Add parser\n\nCo-authored-by: Copilot <copilot@github.com>\n",
+				Trailers: []git.Trailer{{Key: "Co-authored-by", Value: "Copilot <copilot@github.com>"}},
+			},
+			want:      "Co-authored-by: Copilot <copilot@github.com>",
+			wantMatch: true,
+		},
+		{
+			name:      "generated with line",
+			commit:    &git.Commit{Message: "Add parser\n\n🤖 Generated with [Aider](https://aider.chat)\n"},
+			want:      "🤖 Generated with [Aider](https://aider.chat)",
+			wantMatch: true,
+		},
+		{
+			name:      "assisted-by trailer key",
+			commit:    &git.Commit{Message: "Add parser\n\nAssisted-by: some-tool\n", Trailers: []git.Trailer{{Key: "Assisted-by", Value: "some-tool"}}},
+			want:      "Assisted-by: some-tool",
+			wantMatch: true,
+		},
+		{
+			name:      "human co-author",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: Jane <jane@example.com>\n", Trailers: []git.Trailer{{Key: "Co-authored-by", Value: "Jane <jane@example.com>"}}},
+			wantMatch: false,
+		},
+		{
+			name:      "bot address",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: Claude <noreply@anthropic.com>\n", Trailers: []git.Trailer{{Key: "Co-authored-by", Value: "Claude <noreply@anthropic.com>"}}},
+			want:      "Co-authored-by: Claude <noreply@anthropic.com>",
+			wantMatch: true,
+		},
+		{
+			name:      "numbered github bot address",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: devin-ai-integration[bot] <158243242+devin-ai-integration[bot]@users.noreply.github.com>\n"},
+			want:      "Co-authored-by: devin-ai-integration[bot] <158243242+devin-ai-integration[bot]@users.noreply.github.com>",
+			wantMatch: true,
+		},
+		{
+			name:      "human named Claude",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: Claude Dupont <claude.dupont@example.fr>\n", Trailers: []git.Trailer{{Key: "Co-authored-by", Value: "Claude Dupont <claude.dupont@example.fr>"}}},
+			wantMatch: false,
+		},
+		{
+			name:      "human named Devin",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: Devin Walker <devin@walker.dev>\n", Trailers: []git.Trailer{{Key: "Co-authored-by", Value: "Devin Walker <devin@walker.dev>"}}},
+			wantMatch: false,
+		},
+		{
+			name:      "human using a tool name as first name",
+			commit:    &git.Commit{Message: "Add parser\n\nCo-authored-by: Cursor Smith <cursor@example.com>\n"},
+			wantMatch: false,
+		},
+		{
+			name:      "unrelated generated code",
+			commit:    &git.Commit{Message: "Regenerate client\n\nGenerated by openapi-generator\n"},
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchSignature(tt.commit, defaults)
+			if ok != tt.wantMatch || got != tt.want {
+				t.Errorf("matchSignature() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantMatch)
+			}
+		})
+	}
+
+	t.Run("no signatures", func(t *testing.T) {
+		if _, ok := matchSignature(&git.Commit{Message: "Generated with Copilot"}, nil); ok {
+			t.Error("matchSignature() should not match without signatures")
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		if _, err := compileSignatures([]string{"("}); err == nil {
+			t.Error("compileSignatures() should reject an invalid pattern")
+		}
+	})
+}
+
+func TestDetector_AISignatures(t *testing.T) {
+	pair := func(message string) *git.CommitPair {
+		return &git.CommitPair{
+			Previous:  &git.Commit{Hash: "abc123"},
+			Current:   &git.Commit{Hash: "def456", Message: message},
+			TimeDelta: 30 * time.Minute,
+			Stats:     &git.DiffStats{Additions: 5},
+		}
+	}
+
+	t.Run("flags small signed commits", func(t *testing.T) {
+		d, _ := New(&Thresholds{SuspiciousAdditions: 100})
+		result := d.DetectSuspicious(nil, []*git.CommitPair{pair("Tweak\n\nGenerated with ChatGPT\n")}, nil)
+		if len(result) != 1 {
+			t.Fatalf("DetectSuspicious() returned %d results, want 1", len(result))
+		}
+		if result[0].AISignature != "Generated with ChatGPT" {
+			t.Errorf("AISignature = %q, want the generated-with line", result[0].AISignature)
+		}
+		if !contains(result[0].Reasons[0], "AI tool signature in commit message (high confidence)") {
+			t.Errorf("Reasons = %v, want signature reason", result[0].Reasons)
+		}
+	})
+
+	t.Run("custom signatures replace defaults", func(t *testing.T) {
+		d, _ := New(&Thresholds{SuspiciousAdditions: 100, AISignatures: []string{`^x-tool: robot`}})
+		result := d.DetectSuspicious(nil, []*git.CommitPair{pair("Tweak\n\nGenerated with ChatGPT\n"), pair("Tweak\n\nX-Tool: Robot\n")}, nil)
+		if len(result) != 1 || result[0].AISignature != "X-Tool: Robot" {
+			t.Errorf("DetectSuspicious() = %d results, want only the custom signature", len(result))
+		}
+	})
+
+	t.Run("empty signatures disable the check", func(t *testing.T) {
+		d, _ := New(&Thresholds{SuspiciousAdditions: 100, AISignatures: []string{}})
+		if result := d.DetectSuspicious(nil, []*git.CommitPair{pair("Tweak\n\nGenerated with ChatGPT\n")}, nil); len(result) != 0 {
+			t.Errorf("DetectSuspicious() returned %d results, want 0", len(result))
+		}
+	})
+
+	t.Run("unpaired commits", func(t *testing.T) {
+		d, _ := New(&Thresholds{SuspiciousAdditions: 100})
+		root := &git.Commit{Hash: "aaa111", Message: "Initial commit\n\nGenerated with Claude Code\n"}
+		merge := &git.Commit{Hash: "bbb222", Message: "Merge feature\n\nGenerated with Copilot\n", Parents: []string{"x", "y"}}
+		rebased := &git.Commit{Hash: "ccc333", Message: "Rebased\n\nCo-authored-by: Claude <noreply@anthropic.com>\n", Parents: []string{"aaa111"}}
+		human := &git.Commit{Hash: "ddd444", Message: "Fix typo\n", Parents: []string{"ccc333"}}
+
+		result := d.DetectSuspicious([]*git.Commit{human, rebased, merge, root}, nil, nil)
+
+		want := []string{"ccc333", "bbb222", "aaa111"}
+		if len(result) != len(want) {
+			t.Fatalf("DetectSuspicious() returned %d results, want %d", len(result), len(want))
+		}
+		for i, hash := range want {
+			if result[i].Pair.Current.Hash != hash || result[i].AISignature == "" || len(result[i].Reasons) != 1 {
+				t.Errorf("result[%d] = %s %v, want %s with a signature reason", i, result[i].Pair.Current.Hash, result[i].Reasons, hash)
+			}
+		}
+	})
+
+	t.Run("signed merge pair", func(t *testing.T) {
+		d, _ := New(&Thresholds{SuspiciousAdditions: 100})
+		merge := pair("Merge feature\n\nGenerated with Copilot\n")
+		merge.IsMerge = true
+		merge.MergeStats = &git.DiffStats{Additions: 500}
+
+		result := d.DetectSuspicious(nil, []*git.CommitPair{merge}, nil)
+		if len(result) != 1 || len(result[0].Reasons) != 2 || !contains(result[0].Reasons[0], "AI tool signature") {
+			t.Errorf("DetectSuspicious() = %v, want the signature and the merge reasons", result)
+		}
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		if _, err := New(&Thresholds{SuspiciousAdditions: 100, AISignatures: []string{"("}}); err == nil {
+			t.Error("New() should reject an invalid signature")
+		}
+	})
+}
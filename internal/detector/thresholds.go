@@ -1,6 +1,9 @@
 package detector
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Thresholds struct {
 	SuspiciousAdditions int64
@@ -10,6 +13,53 @@ type Thresholds struct {
 	MaxDeletionsPerMin float64
 
 	MinTimeDeltaSeconds int64
+
+	MaxTimestampSkewSeconds int64
+
+	// Languages adds size and velocity thresholds that only look at the lines
+	// of one language, keyed case-insensitively by language name (see
+	// git.DiffStats.Languages). They apply on top of the thresholds above.
+	Languages map[string]LanguageThresholds
+
+	// AISignatures are patterns of AI tool attribution in commit messages,
+	// see DefaultAISignatures which nil stands for. A match flags the commit
+	// regardless of its size. An empty, non-nil slice turns the check off.
+	AISignatures []string
+}
+
+// Signatures returns the effective AI signature patterns.
+func (t *Thresholds) Signatures() []string {
+	if t.AISignatures == nil {
+		return DefaultAISignatures
+	}
+
+	return t.AISignatures
+}
+
+type LanguageThresholds struct {
+	SuspiciousAdditions int64
+	SuspiciousDeletions int64
+
+	MaxAdditionsPerMin float64
+	MaxDeletionsPerMin float64
+}
+
+func (l LanguageThresholds) isZero() bool {
+	return l.SuspiciousAdditions == 0 &&
+		l.SuspiciousDeletions == 0 &&
+		l.MaxAdditionsPerMin == 0 &&
+		l.MaxDeletionsPerMin == 0
+}
+
+// language returns the thresholds configured for a language, if any.
+func (t *Thresholds) language(name string) (LanguageThresholds, bool) {
+	for key, l := range t.Languages {
+		if strings.EqualFold(key, name) {
+			return l, true
+		}
+	}
+
+	return LanguageThresholds{}, false
 }
 
 func (t *Thresholds) Validate() error {
@@ -33,21 +83,46 @@ func (t *Thresholds) Validate() error {
 		return fmt.Errorf("MinTimeDeltaSeconds cannot be negative")
 	}
 
-	if t.SuspiciousAdditions == 0 &&
-		t.SuspiciousDeletions == 0 &&
-		t.MaxAdditionsPerMin == 0 &&
-		t.MaxDeletionsPerMin == 0 &&
-		t.MinTimeDeltaSeconds == 0 {
+	if t.MaxTimestampSkewSeconds < 0 {
+		return fmt.Errorf("MaxTimestampSkewSeconds cannot be negative")
+	}
+
+	for name, l := range t.Languages {
+		if l.SuspiciousAdditions < 0 || l.SuspiciousDeletions < 0 || l.MaxAdditionsPerMin < 0 || l.MaxDeletionsPerMin < 0 {
+			return fmt.Errorf("thresholds for language %s cannot be negative", name)
+		}
+	}
+
+	if _, err := compileSignatures(t.AISignatures); err != nil {
+		return err
+	}
+
+	if t.IsZero() {
 		return fmt.Errorf("at least one threshold must be configured")
 	}
 
 	return nil
 }
 
+// IsZero reports whether no check is configured. The default AI signatures do
+// not count, only those set explicitly.
 func (t *Thresholds) IsZero() bool {
-	return t.SuspiciousAdditions == 0 &&
+	return len(t.AISignatures) == 0 &&
+		t.SuspiciousAdditions == 0 &&
 		t.SuspiciousDeletions == 0 &&
 		t.MaxAdditionsPerMin == 0 &&
 		t.MaxDeletionsPerMin == 0 &&
-		t.MinTimeDeltaSeconds == 0
+		t.MinTimeDeltaSeconds == 0 &&
+		t.MaxTimestampSkewSeconds == 0 &&
+		t.languagesZero()
+}
+
+func (t *Thresholds) languagesZero() bool {
+	for _, l := range t.Languages {
+		if !l.isZero() {
+			return false
+		}
+	}
+
+	return true
 }
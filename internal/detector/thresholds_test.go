@@ -50,6 +50,22 @@ func TestThresholds_Validate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid thresholds only timestamp skew",
+			thresholds: Thresholds{
+				MaxTimestampSkewSeconds: 3600,
+			},
+			expectError: false,
+		},
+		{
+			name: "negative timestamp skew",
+			thresholds: Thresholds{
+				SuspiciousAdditions:     100,
+				MaxTimestampSkewSeconds: -1,
+			},
+			expectError:   true,
+			errorContains: "MaxTimestampSkewSeconds cannot be negative",
+		},
 		{
 			name:          "all zeros invalid",
 			thresholds:    Thresholds{},
@@ -96,6 +112,21 @@ func TestThresholds_Validate(t *testing.T) {
 			expectError:   true,
 			errorContains: "MinTimeDeltaSeconds cannot be negative",
 		},
+		{
+			name: "negative language threshold",
+			thresholds: Thresholds{
+				Languages: map[string]LanguageThresholds{"Go": {SuspiciousAdditions: -1}},
+			},
+			expectError:   true,
+			errorContains: "thresholds for language Go cannot be negative",
+		},
+		{
+			name: "only language thresholds",
+			thresholds: Thresholds{
+				Languages: map[string]LanguageThresholds{"Go": {SuspiciousAdditions: 200}},
+			},
+			expectError: false,
+		},
 		{
 			name: "zero values with one valid",
 			thresholds: Thresholds{
@@ -175,6 +206,41 @@ func TestThresholds_IsZero(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "has timestamp skew",
+			thresholds: Thresholds{
+				MaxTimestampSkewSeconds: 3600,
+			},
+			want: false,
+		},
+		{
+			name: "has language thresholds",
+			thresholds: Thresholds{
+				Languages: map[string]LanguageThresholds{"Go": {MaxAdditionsPerMin: 30}},
+			},
+			want: false,
+		},
+		{
+			name: "empty language thresholds",
+			thresholds: Thresholds{
+				Languages: map[string]LanguageThresholds{"Go": {}},
+			},
+			want: true,
+		},
+		{
+			name: "has AI signatures",
+			thresholds: Thresholds{
+				AISignatures: []string{`^assisted-by:`},
+			},
+			want: false,
+		},
+		{
+			name: "signatures turned off",
+			thresholds: Thresholds{
+				AISignatures: []string{},
+			},
+			want: true,
+		},
 		{
 			name: "all set",
 			thresholds: Thresholds{
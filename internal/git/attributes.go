@@ -0,0 +1,176 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const attributesFileName = ".gitattributes"
+
+// Attributes that mark a path as not hand-written or not meant to be diffed.
+// "binary" is git's built-in macro for "-diff -merge -text".
+var excludingAttributes = []string{"linguist-generated", "linguist-vendored", "diff", "binary"}
+
+// attributeMatcher tells which paths of a tree .gitattributes marks as
+// generated, vendored or non-diffable.
+type attributeMatcher struct {
+	stack []gitattributes.MatchAttribute
+}
+
+// Excluded reports whether filePath is marked linguist-generated,
+// linguist-vendored, -diff or binary.
+func (m *attributeMatcher) Excluded(filePath string) bool {
+	if m == nil || filePath == "" {
+		return false
+	}
+
+	attrs := m.match(strings.Split(filePath, "/"))
+
+	for _, name := range []string{"linguist-generated", "linguist-vendored"} {
+		if attr, ok := attrs[name]; ok && attributeTrue(attr) {
+			return true
+		}
+	}
+
+	if attr, ok := attrs["diff"]; ok && attr.IsUnset() {
+		return true
+	}
+
+	if attr, ok := attrs["binary"]; ok && attr.IsSet() {
+		return true
+	}
+
+	return false
+}
+
+// match resolves the excluding attributes of a path. gitattributes.Matcher
+// lets earlier lines override later ones, while git gives the last matching
+// line precedence, so the stack is walked here in file order instead.
+func (m *attributeMatcher) match(parts []string) map[string]gitattributes.Attribute {
+	attrs := make(map[string]gitattributes.Attribute)
+	for _, ma := range m.stack {
+		if ma.Pattern == nil || !ma.Pattern.Match(parts) {
+			continue
+		}
+		for _, attr := range ma.Attributes {
+			for _, name := range excludingAttributes {
+				if attr.Name() == name {
+					attrs[name] = attr
+				}
+			}
+		}
+	}
+	return attrs
+}
+
+func attributeTrue(attr gitattributes.Attribute) bool {
+	if attr.IsSet() {
+		return true
+	}
+	if attr.IsValueSet() {
+		switch strings.ToLower(attr.Value()) {
+		case "true", "1", "yes":
+			return true
+		}
+	}
+	return false
+}
+
+// loadAttributes reads the .gitattributes files of tree that can apply to
+// paths: the root one and those in every directory leading to a path. It
+// returns nil when there are none.
+func loadAttributes(tree *object.Tree, paths []string) (*attributeMatcher, error) {
+	dirs := make(map[string]bool)
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		for dir := path.Dir(p); ; dir = path.Dir(dir) {
+			if dirs[dir] {
+				break
+			}
+			dirs[dir] = true
+			if dir == "." {
+				break
+			}
+		}
+	}
+
+	// Deeper files take precedence, so they go last on the matcher stack.
+	ordered := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		ordered = append(ordered, dir)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		di, dj := dirDepth(ordered[i]), dirDepth(ordered[j])
+		if di != dj {
+			return di < dj
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	var stack []gitattributes.MatchAttribute
+	for _, dir := range ordered {
+		var domain []string
+		filePath := attributesFileName
+		if dir != "." {
+			domain = strings.Split(dir, "/")
+			filePath = dir + "/" + attributesFileName
+		}
+
+		f, err := tree.File(filePath)
+		if errors.Is(err, object.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+
+		content, err := f.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+
+		// Like git, skip invalid lines and keep the rest of the file.
+		// gitattributes.ReadAttributes would stop at the first one.
+		for _, line := range strings.Split(content, "\n") {
+			attr, err := gitattributes.ParseAttributesLine(line, domain, dir == ".")
+			if err != nil || attr.Name == "" {
+				continue
+			}
+			stack = append(stack, attr)
+		}
+	}
+
+	if len(stack) == 0 {
+		return nil, nil
+	}
+
+	return &attributeMatcher{stack: stack}, nil
+}
+
+func dirDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+func changedPaths(changes object.Changes) []string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			paths = append(paths, change.To.Name)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadAttributes(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, attributesFileName, "*.pb.go linguist-generated\n"+
+		"third_party/** linguist-vendored\n"+
+		"*.snap -diff\n"+
+		"*.dat binary\n"+
+		"*.gen.ts linguist-generated=true\n"+
+		"*.min.js linguist-generated=false\n"+
+		"keep.pb.go -linguist-generated\n"+
+		"bad.txt linguist:generated\n"+
+		"*.lock linguist-generated\n", "Add attributes", base)
+	commitTestFile(t, tmpDir, "api/.gitattributes", "*.go linguist-generated\n", "Add nested attributes", base.Add(time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	tree, err := repo.commitTree(commits[0].Hash)
+	if err != nil {
+		t.Fatalf("commitTree() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{"proto/user.pb.go", true},
+		{"third_party/lib/lib.c", true},
+		{"testdata/out.snap", true},
+		{"assets/blob.dat", true},
+		{"web/client.gen.ts", true},
+		{"web/vendor.min.js", false},
+		{"proto/keep.pb.go", false},
+		{"api/handler.go", true},
+		{"go.lock", true},
+		{"bad.txt", false},
+		{"cmd/main.go", false},
+	}
+
+	paths := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		paths = append(paths, tt.filePath)
+	}
+
+	attrs, err := loadAttributes(tree, paths)
+	if err != nil {
+		t.Fatalf("loadAttributes() unexpected error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			if got := attrs.Excluded(tt.filePath); got != tt.want {
+				t.Errorf("Excluded(%q) = %v, want %v", tt.filePath, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no attributes files", func(t *testing.T) {
+		attrs, err := loadAttributes(tree, []string{"cmd/main.go"})
+		if err != nil {
+			t.Fatalf("loadAttributes() unexpected error = %v", err)
+		}
+		if attrs == nil {
+			t.Fatal("root .gitattributes should have been loaded")
+		}
+
+		var none *attributeMatcher
+		if none.Excluded("cmd/main.go") {
+			t.Error("nil matcher should not exclude anything")
+		}
+	})
+}
+
+func TestGetDiffStats_Attributes(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "main.go", "package main\n", "Initial commit", base)
+	commitTestFile(t, tmpDir, "gen/api.pb.go", "a\nb\nc\nd\n", "Add generated code", base.Add(time.Hour))
+	commitTestFile(t, tmpDir, attributesFileName, "*.pb.go linguist-generated\n", "Mark generated code", base.Add(2*time.Hour))
+	commitTestFile(t, tmpDir, "gen/api.pb.go", "a\nb\nc\nd\ne\nf\n", "Regenerate code", base.Add(3*time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+
+	additions := make(map[string]int64)
+	for _, p := range pairs {
+		additions[p.Current.Message] = p.Stats.Additions
+	}
+
+	// Attributes are read from each commit's own tree, so the file only
+	// counts before it was marked as generated.
+	want := map[string]int64{
+		"Add generated code\n":  4,
+		"Mark generated code\n": 1,
+		"Regenerate code\n":     0,
+	}
+	for msg, n := range want {
+		if additions[msg] != n {
+			t.Errorf("Additions for %q = %d, want %d", msg, additions[msg], n)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheVersion must be bumped whenever DiffStats or the way it is computed
+// changes, so that stale cache files are no longer read.
+const cacheVersion = 7
+
+// diffCache persists the DiffStats of commit pairs. Stats of a pair never
+// change for given settings, so the cache file is keyed by a fingerprint of
+// everything that affects them (exclude patterns, include paths, rename,
+// formatting and language options).
+type diffCache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Stats      *DiffStats `json:"stats"`
+	MergeStats *DiffStats `json:"merge_stats,omitempty"`
+}
+
+type cacheFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*cacheEntry `json:"entries"`
+}
+
+// openDiffCache loads the cache file for repoID and fingerprint from dir. An
+// empty dir disables caching. A missing or unreadable file starts an empty
+// cache, since entries can always be recomputed.
+func openDiffCache(dir, repoID, fingerprint string) *diffCache {
+	if dir == "" {
+		return nil
+	}
+
+	c := &diffCache{
+		path:    filepath.Join(dir, repoID, fingerprint+".json"),
+		entries: make(map[string]*cacheEntry),
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != cacheVersion {
+		return c
+	}
+	if file.Entries != nil {
+		c.entries = file.Entries
+	}
+
+	return c
+}
+
+func cacheKey(pair *CommitPair) string {
+	return pair.Previous.Hash + ".." + pair.Current.Hash
+}
+
+func (c *diffCache) get(pair *CommitPair) (*cacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[cacheKey(pair)]
+	if !ok || entry.Stats == nil || (pair.IsMerge && entry.MergeStats == nil) {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func (c *diffCache) put(pair *CommitPair) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[cacheKey(pair)] = &cacheEntry{Stats: pair.Stats, MergeStats: pair.MergeStats}
+	c.dirty = true
+}
+
+// save writes the cache atomically when entries were added.
+func (c *diffCache) save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("failed to encode diff cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// repositoryID names the cache directory of a repository after its absolute
+// path or URL.
+func repositoryID(location string) string {
+	if !isRemoteURL(location) {
+		if abs, err := filepath.Abs(location); err == nil {
+			location = abs
+		}
+	}
+
+	sum := sha256.Sum256([]byte(location))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fingerprint identifies the settings DiffStats depend on.
+func (r *gitRepository) fingerprint() string {
+	data, _ := json.Marshal(struct {
+		Version          int
+		Excludes         []string
+		IncludePaths     []string
+		RenameSimilarity int
+		IgnoreFormatting bool
+		Languages        map[string]string
+	}{
+		Version:          cacheVersion,
+		Excludes:         r.excludes.patterns,
+		IncludePaths:     r.includePaths,
+		RenameSimilarity: r.renameSimilarity,
+		IgnoreFormatting: r.ignoreFormatting,
+		Languages:        r.languages.overrides,
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
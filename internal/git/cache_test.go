@@ -0,0 +1,112 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetCommitPairs_Cache(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+	cacheDir := t.TempDir()
+
+	commitTestFile(t, tmpDir, "main.go", "package main\n", "Initial commit", base)
+	commitTestFile(t, tmpDir, "main.go", "package main\nfunc main() {}\n", "Add main", base.Add(time.Hour))
+
+	analyze := func(opts *RepositoryOptions) (*gitRepository, []*CommitPair) {
+		t.Helper()
+
+		gitRepo, err := OpenRepository(tmpDir, opts)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		t.Cleanup(func() { gitRepo.Close() })
+		repo := gitRepo.(*gitRepository)
+
+		commits, err := repo.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+		if len(pairs) != 1 {
+			t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+		}
+		return repo, pairs
+	}
+
+	repo, pairs := analyze(&RepositoryOptions{CacheDir: cacheDir})
+	if pairs[0].Stats.Additions != 1 {
+		t.Fatalf("Additions = %d, want 1", pairs[0].Stats.Additions)
+	}
+
+	cachePath := repo.cache.path
+	if filepath.Dir(filepath.Dir(cachePath)) != cacheDir {
+		t.Errorf("cache path = %s, want it under %s/<repo-id>", cachePath, cacheDir)
+	}
+
+	// Tamper with the stored stats to tell cache hits from recomputation.
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Failed to read cache file: %v", err)
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Failed to decode cache file: %v", err)
+	}
+	if len(file.Entries) != 1 {
+		t.Fatalf("len(cache entries) = %d, want 1", len(file.Entries))
+	}
+	for _, entry := range file.Entries {
+		entry.Stats.Additions = 999
+	}
+	data, err = json.Marshal(file)
+	if err != nil {
+		t.Fatalf("Failed to encode cache file: %v", err)
+	}
+	if err := os.WriteFile(cachePath, data, 0o600); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	t.Run("same settings read the cache", func(t *testing.T) {
+		_, pairs := analyze(&RepositoryOptions{CacheDir: cacheDir})
+		if pairs[0].Stats.Additions != 999 {
+			t.Errorf("Additions = %d, want cached 999", pairs[0].Stats.Additions)
+		}
+	})
+
+	t.Run("changed exclusions invalidate the cache", func(t *testing.T) {
+		repo, pairs := analyze(&RepositoryOptions{CacheDir: cacheDir, ExcludeFiles: []string{"*.md"}})
+		if pairs[0].Stats.Additions != 1 {
+			t.Errorf("Additions = %d, want recomputed 1", pairs[0].Stats.Additions)
+		}
+		if repo.cache.path == cachePath {
+			t.Error("different exclusions should use a different cache file")
+		}
+	})
+
+	t.Run("no cache dir", func(t *testing.T) {
+		repo, pairs := analyze(nil)
+		if repo.cache != nil {
+			t.Error("cache should be disabled without a cache dir")
+		}
+		if pairs[0].Stats.Additions != 1 {
+			t.Errorf("Additions = %d, want 1", pairs[0].Stats.Additions)
+		}
+	})
+
+	t.Run("corrupt cache file is ignored", func(t *testing.T) {
+		if err := os.WriteFile(cachePath, []byte("not json"), 0o600); err != nil {
+			t.Fatalf("Failed to write cache file: %v", err)
+		}
+		_, pairs := analyze(&RepositoryOptions{CacheDir: cacheDir})
+		if pairs[0].Stats.Additions != 1 {
+			t.Errorf("Additions = %d, want recomputed 1", pairs[0].Stats.Additions)
+		}
+	})
+}
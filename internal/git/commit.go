@@ -1,6 +1,9 @@
 package git
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type Commit struct {
 	Hash      string
@@ -9,13 +12,52 @@ type Commit struct {
 	Timestamp time.Time
 	Message   string
 	Parents   []string
+
+	// Trailers are parsed from the trailer block of Message, e.g.
+	// Co-authored-by lines.
+	Trailers []Trailer
+
+	Committer          string
+	CommitterEmail     string
+	AuthorTimestamp    time.Time
+	CommitterTimestamp time.Time
+
+	// Refs lists the refs whose history contains the commit. It is only
+	// filled when several refs are walked (CommitOptions.AllRefs or RefPatterns).
+	Refs []string
+
+	// Signature is nil for unsigned commits.
+	Signature *Signature
+
+	// Shallow marks a boundary commit of a shallow clone. Its parents were
+	// never fetched, so it cannot be paired or diffed.
+	Shallow bool
+
+	// timeSource remembers which clock Timestamp was taken from so that
+	// parents loaded later for pairing use the same one.
+	timeSource TimeSource
 }
 
+// TimeSource selects which commit clock drives Commit.Timestamp and thereby
+// CommitPair.TimeDelta.
+type TimeSource string
+
+const (
+	TimeSourceAuthor    TimeSource = "author"
+	TimeSourceCommitter TimeSource = "committer"
+)
+
 type CommitPair struct {
 	Previous  *Commit
 	Current   *Commit
 	TimeDelta time.Duration
 	Stats     *DiffStats
+
+	// IsMerge marks pairs whose Current has several parents. Stats then holds
+	// the diff against the first parent and MergeStats only the lines the merge
+	// itself introduced, i.e. lines not present in any of its parents.
+	IsMerge    bool
+	MergeStats *DiffStats
 }
 
 type DiffStats struct {
@@ -25,9 +67,114 @@ type DiffStats struct {
 	TotalAdditions    int64
 	TotalDeletions    int64
 	FilesChangedTotal int
+
+	// Renames and Copies count the renamed and copied files among the
+	// filtered ones. Their lines only count the edits made on top.
+	Renames int
+	Copies  int
+
+	// FormattingAdditions and FormattingDeletions count the filtered lines
+	// that only changed whitespace: lines that differ from a line on the
+	// other side of the same hunk but match it once whitespace is ignored.
+	FormattingAdditions int64
+	FormattingDeletions int64
+
+	// BinaryFiles counts the filtered binary files that changed and
+	// BinarySizeDelta how many bytes they grew by in total. Binary files
+	// never contribute lines.
+	BinaryFiles     int
+	BinarySizeDelta int64
+
+	// SubmoduleUpdates counts the filtered submodules whose recorded commit
+	// changed. A bump has no lines of its own; the commits it pulls in are
+	// part of the submodule's history.
+	SubmoduleUpdates int
+
+	// Files breaks the change down per file, excluded files included.
+	Files []FileStats
+
+	// Languages breaks the filtered additions and deletions down by the
+	// language of the files they were made in.
+	Languages map[string]LanguageStats
+}
+
+type LanguageStats struct {
+	Additions int64
+	Deletions int64
+}
+
+// FileStatus tells whether a file was added, modified or deleted. Renamed
+// files are modified and copied files added; both keep their source in
+// FileStats.OldPath.
+type FileStatus string
+
+const (
+	FileAdded    FileStatus = "added"
+	FileModified FileStatus = "modified"
+	FileDeleted  FileStatus = "deleted"
+)
+
+type FileStats struct {
+	Path      string
+	OldPath   string
+	Status    FileStatus
+	Language  string
+	Additions int64
+	Deletions int64
+
+	// Excluded marks files left out of the filtered counts by exclude
+	// patterns, include paths or .gitattributes. Their lines are only part of
+	// the totals.
+	Excluded bool
+
+	// Submodule marks a change of the commit recorded for a submodule.
+	Submodule bool
+}
+
+// TopFiles returns up to n files that changed the most lines, filtered files
+// before excluded ones. A non-positive n returns all of them.
+func (s *DiffStats) TopFiles(n int) []FileStats {
+	files := make([]FileStats, len(s.Files))
+	copy(files, s.Files)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Excluded != files[j].Excluded {
+			return !files[i].Excluded
+		}
+		ci := files[i].Additions + files[i].Deletions
+		cj := files[j].Additions + files[j].Deletions
+		if ci != cj {
+			return ci > cj
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	if n > 0 && len(files) > n {
+		files = files[:n]
+	}
+
+	return files
 }
 
 type CommitOptions struct {
-	Branch   string
-	MaxDepth int
+	Branch     string
+	MaxDepth   int
+	TimeSource TimeSource
+
+	// Range limits the walk to a git-style "A..B" revision range. Since and
+	// Until bound Commit.Timestamp; zero values leave them open.
+	Range string
+	Since time.Time
+	Until time.Time
+
+	// AllRefs walks every branch, remote-tracking branch and tag instead of a
+	// single branch. RefPatterns narrows that set with globs such as
+	// refs/heads/feature/* and implies AllRefs.
+	AllRefs     bool
+	RefPatterns []string
+
+	// ExcludeRevisions skips the commits reachable from these revisions, like
+	// ^rev in git log, e.g. the tips analyzed by a previous run. A revision
+	// that no longer resolves fails with ErrUnknownExcludedRevision.
+	ExcludeRevisions []string
 }
@@ -175,3 +175,41 @@ func TestCommitOptionsStructure(t *testing.T) {
 		}
 	})
 }
+
+func TestDiffStats_TopFiles(t *testing.T) {
+	stats := &DiffStats{Files: []FileStats{
+		{Path: "small.go", Additions: 2},
+		{Path: "package-lock.json", Additions: 900, Excluded: true},
+		{Path: "big.go", Additions: 300, Deletions: 20},
+		{Path: "b.go", Additions: 10},
+		{Path: "a.go", Deletions: 10},
+	}}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"filtered files first by churn", 3, []string{"big.go", "a.go", "b.go"}},
+		{"excluded files last", 0, []string{"big.go", "a.go", "b.go", "small.go", "package-lock.json"}},
+		{"limit above count", 10, []string{"big.go", "a.go", "b.go", "small.go", "package-lock.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stats.TopFiles(tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len(TopFiles()) = %d, want %d", len(got), len(tt.want))
+			}
+			for i, f := range got {
+				if f.Path != tt.want[i] {
+					t.Errorf("TopFiles()[%d] = %s, want %s", i, f.Path, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if stats.Files[0].Path != "small.go" {
+		t.Error("TopFiles() should not reorder Files")
+	}
+}
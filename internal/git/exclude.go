@@ -0,0 +1,124 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// IgnoreFileName is read from the root of the analyzed repository and holds
+// exclude patterns in .gitignore syntax.
+const IgnoreFileName = ".vibectorignore"
+
+// excludeMatcher matches paths against exclude patterns with gitignore
+// semantics: "**" spans directories, a leading "/" anchors a pattern to the
+// repository root, a trailing "/" matches directories, "!" re-includes and
+// later patterns take precedence over earlier ones.
+type excludeMatcher struct {
+	patterns []string
+	matcher  gitignore.Matcher
+}
+
+func newExcludeMatcher(patterns []string) *excludeMatcher {
+	parsed := make([]gitignore.Pattern, 0, len(patterns))
+	kept := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		kept = append(kept, p)
+		parsed = append(parsed, gitignore.ParsePattern(p, nil))
+	}
+
+	return &excludeMatcher{
+		patterns: kept,
+		matcher:  gitignore.NewMatcher(parsed),
+	}
+}
+
+func (m *excludeMatcher) Match(filePath string) bool {
+	if len(m.patterns) == 0 {
+		return false
+	}
+	return m.matcher.Match(strings.Split(filePath, "/"), false)
+}
+
+// parseIgnoreFile returns the patterns of a .gitignore style file, skipping
+// blank lines and comments.
+func parseIgnoreFile(r io.Reader) ([]string, error) {
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// loadIgnoreFile reads IgnoreFileName from the root of the repository. A
+// missing file yields no patterns.
+func loadIgnoreFile(r *git.Repository) ([]string, error) {
+	f, err := openRootFile(r, IgnoreFileName)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseIgnoreFile(f)
+}
+
+// openRootFile opens a file at the root of the worktree when there is one and
+// of the HEAD tree otherwise (bare repositories, in-memory clones). It returns
+// nil without an error when the file does not exist.
+func openRootFile(r *git.Repository, name string) (io.ReadCloser, error) {
+	if wt, err := r.Worktree(); err == nil {
+		f, err := wt.Filesystem.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		return nil, nil
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, nil
+	}
+
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	f, err := commit.File(name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	reader, err := f.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return reader, nil
+}
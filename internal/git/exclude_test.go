@@ -0,0 +1,194 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExcludeMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		filePath string
+		want     bool
+	}{
+		{
+			name:     "basename pattern matches at any depth",
+			patterns: []string{"*.log"},
+			filePath: "logs/deep/error.log",
+			want:     true,
+		},
+		{
+			name:     "double star directory",
+			patterns: []string{"vendor/**"},
+			filePath: "vendor/github.com/pkg/errors/errors.go",
+			want:     true,
+		},
+		{
+			name:     "double star does not match sibling",
+			patterns: []string{"vendor/**"},
+			filePath: "vendored.go",
+			want:     false,
+		},
+		{
+			name:     "leading double star",
+			patterns: []string{"**/testdata/*.json"},
+			filePath: "pkg/parser/testdata/case1.json",
+			want:     true,
+		},
+		{
+			name:     "leading double star other extension",
+			patterns: []string{"**/testdata/*.json"},
+			filePath: "pkg/parser/testdata/case1.go",
+			want:     false,
+		},
+		{
+			name:     "anchored pattern only matches at root",
+			patterns: []string{"/build"},
+			filePath: "cmd/build/main.go",
+			want:     false,
+		},
+		{
+			name:     "anchored pattern matches root directory",
+			patterns: []string{"/build"},
+			filePath: "build/output.js",
+			want:     true,
+		},
+		{
+			name:     "directory pattern matches contents",
+			patterns: []string{"generated/"},
+			filePath: "api/generated/types.go",
+			want:     true,
+		},
+		{
+			name:     "directory pattern does not match file",
+			patterns: []string{"generated/"},
+			filePath: "api/generated",
+			want:     false,
+		},
+		{
+			name:     "negation re-includes file",
+			patterns: []string{"*.lock", "!important.lock"},
+			filePath: "important.lock",
+			want:     false,
+		},
+		{
+			name:     "negation keeps other matches excluded",
+			patterns: []string{"*.lock", "!important.lock"},
+			filePath: "yarn.lock",
+			want:     true,
+		},
+		{
+			name:     "later patterns take precedence",
+			patterns: []string{"!important.lock", "*.lock"},
+			filePath: "important.lock",
+			want:     true,
+		},
+		{
+			name:     "comments and blank patterns are ignored",
+			patterns: []string{"# *.go", "", "  "},
+			filePath: "main.go",
+			want:     false,
+		},
+		{
+			name:     "no patterns",
+			patterns: nil,
+			filePath: "main.go",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newExcludeMatcher(tt.patterns)
+			if got := m.Match(tt.filePath); got != tt.want {
+				t.Errorf("Match(%q) with %v = %v, want %v", tt.filePath, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	content := "# generated code\n*.pb.go\r\n\nvendor/**\n!vendor/keep.go\n"
+
+	patterns, err := parseIgnoreFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseIgnoreFile() unexpected error = %v", err)
+	}
+
+	want := []string{"*.pb.go", "vendor/**", "!vendor/keep.go"}
+	if strings.Join(patterns, ",") != strings.Join(want, ",") {
+		t.Errorf("parseIgnoreFile() = %v, want %v", patterns, want)
+	}
+}
+
+func TestOpenRepository_IgnoreFile(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, IgnoreFileName, "vendor/**\n", "Add ignore file", base)
+	commitTestFile(t, tmpDir, "vendor/lib/lib.go", "l1\nl2\nl3\n", "Vendor lib", base.Add(time.Hour))
+
+	t.Run("worktree ignore file combined with options", func(t *testing.T) {
+		gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{ExcludeFiles: []string{"!vendor/lib/lib.go"}})
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		if !repo.shouldExcludeFile("vendor/other/other.go") {
+			t.Error("vendor/other/other.go should be excluded by .vibectorignore")
+		}
+		if repo.shouldExcludeFile("vendor/lib/lib.go") {
+			t.Error("vendor/lib/lib.go should be re-included by ExcludeFiles")
+		}
+	})
+
+	t.Run("ignore file read from HEAD of bare repository", func(t *testing.T) {
+		bareDir := filepath.Join(t.TempDir(), "repo.git")
+		runGit(t, tmpDir, nil, "clone", "-q", "--bare", tmpDir, bareDir)
+
+		gitRepo, err := OpenRepository(bareDir, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		commits, err := repo.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+		if len(pairs) != 1 {
+			t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+		}
+		if pairs[0].Stats.Additions != 0 || pairs[0].Stats.TotalAdditions != 3 {
+			t.Errorf("Stats = +%d filtered / +%d total, want +0 / +3", pairs[0].Stats.Additions, pairs[0].Stats.TotalAdditions)
+		}
+	})
+
+	t.Run("missing ignore file", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(tmpDir, IgnoreFileName)); err != nil {
+			t.Fatalf("Failed to remove ignore file: %v", err)
+		}
+
+		gitRepo, err := OpenRepository(tmpDir, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		if repo.shouldExcludeFile("vendor/lib/lib.go") {
+			t.Error("nothing should be excluded without an ignore file")
+		}
+	})
+}
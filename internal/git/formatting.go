@@ -0,0 +1,35 @@
+package git
+
+import "strings"
+
+// formattingLines counts the added and deleted lines of one hunk that only
+// differ in whitespace, as with git diff -w: a deleted line and a later added
+// line pair up, in order, when they are equal once all whitespace is removed
+// but not before. Blank lines never count, and neither do lines that match
+// the other side exactly, such as a repeated closing brace.
+func formattingLines(added, deleted []string) (int64, int64) {
+	var formatting int64
+	next := 0
+	for _, line := range added {
+		key := stripWhitespace(line)
+		if key == "" {
+			continue
+		}
+		for i := next; i < len(deleted); i++ {
+			if stripWhitespace(deleted[i]) != key {
+				continue
+			}
+			if deleted[i] != line {
+				formatting++
+			}
+			next = i + 1
+			break
+		}
+	}
+
+	return formatting, formatting
+}
+
+func stripWhitespace(line string) string {
+	return strings.Join(strings.Fields(line), "")
+}
@@ -0,0 +1,173 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormattingLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		added       []string
+		deleted     []string
+		wantAdded   int64
+		wantDeleted int64
+	}{
+		{
+			name:        "reindented lines",
+			added:       []string{"\tif x {", "\t\treturn y", "\t}"},
+			deleted:     []string{"  if x {", "    return y", "  }"},
+			wantAdded:   3,
+			wantDeleted: 3,
+		},
+		{
+			name:        "spacing inside line",
+			added:       []string{"a := b + c"},
+			deleted:     []string{"a:=b+c"},
+			wantAdded:   1,
+			wantDeleted: 1,
+		},
+		{
+			name:        "real edit is not formatting",
+			added:       []string{"return x + 1"},
+			deleted:     []string{"return x"},
+			wantAdded:   0,
+			wantDeleted: 0,
+		},
+		{
+			name:        "blank lines are not formatting",
+			added:       []string{"   ", "code()", ""},
+			deleted:     []string{"\t"},
+			wantAdded:   0,
+			wantDeleted: 0,
+		},
+		{
+			name:        "rewritten logic with identical lines is not formatting",
+			added:       []string{"\tif err := run(); err != nil {", "\t\treturn err", "\t}", "", "\treturn nil", "}"},
+			deleted:     []string{"\tif ok := check(); !ok {", "\t\treturn errFailed", "\t}", "", "\treturn nil", "}"},
+			wantAdded:   0,
+			wantDeleted: 0,
+		},
+		{
+			name:        "pairs keep their order",
+			added:       []string{"\tb()", "\ta()"},
+			deleted:     []string{"  a()", "  b()"},
+			wantAdded:   1,
+			wantDeleted: 1,
+		},
+		{
+			name:        "duplicates pair up once",
+			added:       []string{"}", "}", "}"},
+			deleted:     []string{" }"},
+			wantAdded:   1,
+			wantDeleted: 1,
+		},
+		{
+			name:        "no lines",
+			wantAdded:   0,
+			wantDeleted: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAdded, gotDeleted := formattingLines(tt.added, tt.deleted)
+			if gotAdded != tt.wantAdded || gotDeleted != tt.wantDeleted {
+				t.Errorf("formattingLines() = %d, %d, want %d, %d", gotAdded, gotDeleted, tt.wantAdded, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestGetCommitPairs_Formatting(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "main.go", "func main() {\n  a:=1\n  b:=2\n}\n", "Initial commit", base)
+	commitTestFile(t, tmpDir, "main.go", "func main() {\n\ta := 1\n\tb := 2\n\tc := 3\n}\n", "Reformat and add line", base.Add(time.Hour))
+
+	tests := []struct {
+		name          string
+		opts          *RepositoryOptions
+		wantAdditions int64
+		wantDeletions int64
+	}{
+		{"formatting counted", nil, 3, 2},
+		{"formatting ignored", &RepositoryOptions{IgnoreFormatting: true}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitRepo, err := OpenRepository(tmpDir, tt.opts)
+			if err != nil {
+				t.Fatalf("OpenRepository() unexpected error = %v", err)
+			}
+			defer gitRepo.Close()
+			repo := gitRepo.(*gitRepository)
+
+			commits, err := repo.GetCommits(nil)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			pairs, err := repo.GetCommitPairs(commits)
+			if err != nil {
+				t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+			}
+			if len(pairs) != 1 {
+				t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+			}
+
+			stats := pairs[0].Stats
+			if stats.Additions != tt.wantAdditions || stats.Deletions != tt.wantDeletions {
+				t.Errorf("Stats = +%d -%d, want +%d -%d", stats.Additions, stats.Deletions, tt.wantAdditions, tt.wantDeletions)
+			}
+			if stats.FormattingAdditions != 2 || stats.FormattingDeletions != 2 {
+				t.Errorf("Formatting = +%d -%d, want +2 -2", stats.FormattingAdditions, stats.FormattingDeletions)
+			}
+			if stats.TotalAdditions != 3 || stats.TotalDeletions != 2 {
+				t.Errorf("Total = +%d -%d, want +3 -2", stats.TotalAdditions, stats.TotalDeletions)
+			}
+		})
+	}
+}
+
+func TestGetCommitPairs_FormattingWithinHunk(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	// The reindented call moves to another hunk, and the rewritten function
+	// keeps its closing lines unchanged: neither is formatting.
+	before := "func a() error {\n  cleanup()\n\treturn nil\n}\n\n" + numberedLines("keep", 10) +
+		"func b() error {\n\tif ok := check(); !ok {\n\t\treturn errFailed\n\t}\n\treturn nil\n}\n"
+	after := "func a() error {\n\treturn nil\n}\n\n" + numberedLines("keep", 10) +
+		"func b() error {\n\tif err := run(); err != nil {\n\t\treturn err\n\t}\n\tcleanup()\n\treturn nil\n}\n"
+	commitTestFile(t, tmpDir, "main.go", before, "Initial commit", base)
+	commitTestFile(t, tmpDir, "main.go", after, "Rewrite b", base.Add(time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{IgnoreFormatting: true})
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+	if len(pairs) != 1 {
+		t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+	}
+
+	stats := pairs[0].Stats
+	if stats.FormattingAdditions != 0 || stats.FormattingDeletions != 0 {
+		t.Errorf("Formatting = +%d -%d, want +0 -0", stats.FormattingAdditions, stats.FormattingDeletions)
+	}
+	if stats.Additions != stats.TotalAdditions || stats.Deletions != stats.TotalDeletions {
+		t.Errorf("Stats = +%d -%d, want the totals +%d -%d", stats.Additions, stats.Deletions, stats.TotalAdditions, stats.TotalDeletions)
+	}
+}
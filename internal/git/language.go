@@ -0,0 +1,114 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// OtherLanguage is reported for files whose language is not known.
+const OtherLanguage = "Other"
+
+var languagesByExtension = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".mts":   "TypeScript",
+	".cts":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".py":    "Python",
+	".rb":    "Ruby",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".kts":   "Kotlin",
+	".swift": "Swift",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".cxx":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".php":   "PHP",
+	".scala": "Scala",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".sql":   "SQL",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "CSS",
+	".vue":   "Vue",
+	".proto": "Protocol Buffers",
+	".md":    "Markdown",
+	".rst":   "reStructuredText",
+	".txt":   "Text",
+	".yml":   "YAML",
+	".yaml":  "YAML",
+	".json":  "JSON",
+	".toml":  "TOML",
+	".xml":   "XML",
+}
+
+var languagesByFileName = map[string]string{
+	"Dockerfile":  "Dockerfile",
+	"Makefile":    "Makefile",
+	"GNUmakefile": "Makefile",
+	"go.mod":      "Go Module",
+	"go.sum":      "Go Module",
+}
+
+// languageClassifier maps file paths to languages by file name and
+// extension. Overrides take precedence over the built-in extensions.
+type languageClassifier struct {
+	overrides map[string]string
+}
+
+// newLanguageClassifier builds a classifier from extension overrides such as
+// ".tmpl" -> "Go". Extensions are matched case-insensitively, with or without
+// the leading dot. Language names that match a known language regardless of
+// case use its canonical spelling.
+func newLanguageClassifier(overrides map[string]string) *languageClassifier {
+	canonical := make(map[string]string)
+	for _, lang := range languagesByExtension {
+		canonical[strings.ToLower(lang)] = lang
+	}
+	for _, lang := range languagesByFileName {
+		canonical[strings.ToLower(lang)] = lang
+	}
+
+	c := &languageClassifier{overrides: make(map[string]string, len(overrides))}
+	for ext, lang := range overrides {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if name, ok := canonical[strings.ToLower(lang)]; ok {
+			lang = name
+		}
+		c.overrides[ext] = lang
+	}
+
+	return c
+}
+
+func (c *languageClassifier) classify(filePath string) string {
+	base := path.Base(filePath)
+	ext := strings.ToLower(path.Ext(base))
+
+	if c != nil {
+		if lang, ok := c.overrides[ext]; ok {
+			return lang
+		}
+	}
+	if lang, ok := languagesByFileName[base]; ok {
+		return lang
+	}
+	if lang, ok := languagesByExtension[ext]; ok {
+		return lang
+	}
+
+	return OtherLanguage
+}
@@ -0,0 +1,41 @@
+package git
+
+import "testing"
+
+func TestLanguageClassifier(t *testing.T) {
+	classifier := newLanguageClassifier(map[string]string{
+		".tmpl": "go",
+		"GEN":   "Generated",
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "Go"},
+		{"web/src/App.tsx", "TypeScript"},
+		{"docs/README.md", "Markdown"},
+		{".github/workflows/ci.yml", "YAML"},
+		{"build/Dockerfile", "Dockerfile"},
+		{"UPPER.GO", "Go"},
+		{"templates/page.tmpl", "Go"},
+		{"api/types.gen", "Generated"},
+		{"LICENSE", OtherLanguage},
+		{"data.unknown", OtherLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := classifier.classify(tt.path); got != tt.want {
+				t.Errorf("classify(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("nil classifier", func(t *testing.T) {
+		var c *languageClassifier
+		if got := c.classify("main.go"); got != "Go" {
+			t.Errorf("classify() = %q, want Go", got)
+		}
+	})
+}
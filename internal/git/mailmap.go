@@ -0,0 +1,164 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// MailmapFileName is read from the root of the analyzed repository, like git
+// does for log and shortlog.
+const MailmapFileName = ".mailmap"
+
+// mailmap maps the names and emails commits were recorded with to canonical
+// ones. Emails and names are matched case-insensitively.
+type mailmap struct {
+	// byEmail holds entries that apply to every name used with an email,
+	// byIdentity entries that only apply to one name and email pair.
+	byEmail    map[string]mailmapEntry
+	byIdentity map[string]mailmapEntry
+}
+
+type mailmapEntry struct {
+	name  string
+	email string
+}
+
+func newMailmap() *mailmap {
+	return &mailmap{
+		byEmail:    make(map[string]mailmapEntry),
+		byIdentity: make(map[string]mailmapEntry),
+	}
+}
+
+func identityKey(name, email string) string {
+	return strings.ToLower(name) + "\x00" + strings.ToLower(email)
+}
+
+// parse adds the entries of a mailmap file. Later entries override earlier
+// ones. The supported forms are:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func (m *mailmap) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		name1, email1, rest, ok := parseMailmapIdentity(line)
+		if !ok {
+			continue
+		}
+		name2, email2, _, ok := parseMailmapIdentity(rest)
+
+		switch {
+		case !ok:
+			m.byEmail[strings.ToLower(email1)] = m.merge(m.byEmail[strings.ToLower(email1)], name1, "")
+		case name2 == "":
+			m.byEmail[strings.ToLower(email2)] = m.merge(m.byEmail[strings.ToLower(email2)], name1, email1)
+		default:
+			m.byIdentity[identityKey(name2, email2)] = mailmapEntry{name: name1, email: email1}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read mailmap: %w", err)
+	}
+
+	return nil
+}
+
+// merge lets separate lines set the name and the email of one address.
+func (m *mailmap) merge(entry mailmapEntry, name, email string) mailmapEntry {
+	if name != "" {
+		entry.name = name
+	}
+	if email != "" {
+		entry.email = email
+	}
+	return entry
+}
+
+// parseMailmapIdentity reads an optional name followed by an <email> and
+// returns what follows it.
+func parseMailmapIdentity(s string) (name, email, rest string, ok bool) {
+	open := strings.Index(s, "<")
+	if open < 0 {
+		return "", "", "", false
+	}
+	end := strings.Index(s[open:], ">")
+	if end < 0 {
+		return "", "", "", false
+	}
+
+	name = strings.TrimSpace(s[:open])
+	email = strings.TrimSpace(s[open+1 : open+end])
+	return name, email, s[open+end+1:], true
+}
+
+// resolve returns the canonical name and email of an identity.
+func (m *mailmap) resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	entry, ok := m.byIdentity[identityKey(name, email)]
+	if !ok {
+		entry, ok = m.byEmail[strings.ToLower(email)]
+	}
+	if !ok {
+		return name, email
+	}
+
+	if entry.name != "" {
+		name = entry.name
+	}
+	if entry.email != "" {
+		email = entry.email
+	}
+	return name, email
+}
+
+// loadMailmap reads MailmapFileName from the repository and then the optional
+// extra file, whose entries take precedence. It returns nil when neither has
+// any entries.
+func loadMailmap(r *git.Repository, extraFile string) (*mailmap, error) {
+	m := newMailmap()
+
+	f, err := openRootFile(r, MailmapFileName)
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
+		defer f.Close()
+		if err := m.parse(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if extraFile != "" {
+		extra, err := os.Open(extraFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mailmap file: %w", err)
+		}
+		defer extra.Close()
+		if err := m.parse(extra); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.byEmail) == 0 && len(m.byIdentity) == 0 {
+		return nil, nil
+	}
+
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	m := newMailmap()
+	content := `# Team mailmap
+Jane Doe <jane@work.example>
+Jane Doe <jane@work.example> <jane@laptop.local>
+<john@work.example> <JOHN@old.example>   # comment
+John Smith <john@work.example> johnny <shared@example.com>
+Bad line without email
+`
+	if err := m.parse(strings.NewReader(content)); err != nil {
+		t.Fatalf("parse() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		author    string
+		email     string
+		wantName  string
+		wantEmail string
+	}{
+		{"name for email", "jdoe", "jane@work.example", "Jane Doe", "jane@work.example"},
+		{"name and email for old email", "jane", "jane@laptop.local", "Jane Doe", "jane@work.example"},
+		{"email only, case-insensitive", "John", "john@OLD.example", "John", "john@work.example"},
+		{"name and email pair", "Johnny", "shared@example.com", "John Smith", "john@work.example"},
+		{"other name on shared email", "someone", "shared@example.com", "someone", "shared@example.com"},
+		{"unknown identity", "Alice", "alice@example.com", "Alice", "alice@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotEmail := m.resolve(tt.author, tt.email)
+			if gotName != tt.wantName || gotEmail != tt.wantEmail {
+				t.Errorf("resolve() = %s <%s>, want %s <%s>", gotName, gotEmail, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+
+	t.Run("nil mailmap", func(t *testing.T) {
+		var nilMap *mailmap
+		if name, email := nilMap.resolve("Alice", "alice@example.com"); name != "Alice" || email != "alice@example.com" {
+			t.Errorf("resolve() = %s <%s>, want identity unchanged", name, email)
+		}
+	})
+}
+
+func TestGetCommits_Mailmap(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, MailmapFileName, "Test User <test@work.example> <test@example.com>\n", "Add mailmap", base)
+
+	extra := filepath.Join(t.TempDir(), "extra.mailmap")
+	if err := os.WriteFile(extra, []byte("Canonical User <test@work.example> <test@example.com>\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write extra mailmap: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      *RepositoryOptions
+		wantName  string
+		wantEmail string
+	}{
+		{"repository mailmap", nil, "Test User", "test@work.example"},
+		{"extra mailmap takes precedence", &RepositoryOptions{MailmapFile: extra}, "Canonical User", "test@work.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := OpenRepository(tmpDir, tt.opts)
+			if err != nil {
+				t.Fatalf("OpenRepository() unexpected error = %v", err)
+			}
+			defer repo.Close()
+
+			commits, err := repo.GetCommits(nil)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			c := commits[0]
+			if c.Author != tt.wantName || c.Email != tt.wantEmail {
+				t.Errorf("Author = %s <%s>, want %s <%s>", c.Author, c.Email, tt.wantName, tt.wantEmail)
+			}
+			if c.Committer != tt.wantName || c.CommitterEmail != tt.wantEmail {
+				t.Errorf("Committer = %s <%s>, want %s <%s>", c.Committer, c.CommitterEmail, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+
+	t.Run("missing extra mailmap", func(t *testing.T) {
+		if _, err := OpenRepository(tmpDir, &RepositoryOptions{MailmapFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+			t.Error("OpenRepository() should fail for a missing mailmap file")
+		}
+	})
+}
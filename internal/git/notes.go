@@ -0,0 +1,324 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// DefaultNotesRef is the notes ref vibector annotate writes to. It is shown
+// by git log --notes=vibector.
+const DefaultNotesRef = "refs/notes/vibector"
+
+// notesIdentity signs notes commits when the repository has no user
+// configured.
+var notesIdentity = object.Signature{Name: "vibector", Email: "vibector@localhost"}
+
+// ReadNotes returns the notes stored under ref keyed by the full hash of the
+// commit they annotate. A missing ref has no notes.
+func (r *gitRepository) ReadNotes(ref string) (map[string]string, error) {
+	notes := make(map[string]string)
+
+	tree, _, err := r.notesTree(ref)
+	if err != nil || tree == nil {
+		return notes, err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		hash, ok := notedCommit(f.Name)
+		if !ok {
+			return nil
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read note of %s: %w", hash, err)
+		}
+		notes[hash] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// WriteNotes adds or replaces the notes of the commits in notes with a single
+// commit on ref, keeping every other entry of its tree. It fails if ref moved,
+// or was created, while the notes were written.
+func (r *gitRepository) WriteNotes(ref string, notes map[string]string, message string) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	if _, inMemory := r.repo.Storer.(*memory.Storage); inMemory {
+		return fmt.Errorf("cannot write notes to a repository cloned into memory")
+	}
+
+	tree, parent, err := r.notesTree(ref)
+	if err != nil {
+		return err
+	}
+
+	// Notes are written where they already are, or into the fanout
+	// directories the tree already has, like git does. Every other entry,
+	// such as notes of other commits or files git notes does not own, is
+	// kept as is.
+	paths := make(map[string]string)
+	if tree != nil {
+		err = tree.Files().ForEach(func(f *object.File) error {
+			if hash, ok := notedCommit(f.Name); ok {
+				paths[hash] = f.Name
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read notes: %w", err)
+		}
+	}
+
+	files := make(map[string]plumbing.Hash, len(notes))
+	for hash, note := range notes {
+		blob, err := r.writeObject(plumbing.BlobObject, []byte(note))
+		if err != nil {
+			return fmt.Errorf("failed to write note of %s: %w", hash, err)
+		}
+		notePath, ok := paths[hash]
+		if !ok {
+			notePath = r.fanoutPath(tree, hash)
+		}
+		files[notePath] = blob
+	}
+
+	treeHash, err := r.updateTree(tree, files)
+	if err != nil {
+		return fmt.Errorf("failed to write notes tree: %w", err)
+	}
+
+	signature := r.identity()
+	commit := &object.Commit{
+		Author:    signature,
+		Committer: signature,
+		Message:   message,
+		TreeHash:  treeHash,
+	}
+	if parent != nil {
+		commit.ParentHashes = []plumbing.Hash{parent.Hash()}
+	}
+
+	commitObj := r.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObj); err != nil {
+		return fmt.Errorf("failed to encode notes commit: %w", err)
+	}
+	commitHash, err := r.repo.Storer.SetEncodedObject(commitObj)
+	if err != nil {
+		return fmt.Errorf("failed to write notes commit: %w", err)
+	}
+
+	reference := plumbing.NewHashReference(plumbing.ReferenceName(ref), commitHash)
+	if parent != nil {
+		err = r.repo.Storer.CheckAndSetReference(reference, parent)
+	} else {
+		err = r.createReference(reference)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// notesTree returns the tree of the notes commit ref points to and the ref
+// itself, or nils when it does not exist yet.
+func (r *gitRepository) notesTree(ref string) (*object.Tree, *plumbing.Reference, error) {
+	if !strings.HasPrefix(ref, "refs/notes/") {
+		return nil, nil, fmt.Errorf("invalid notes ref %q: must start with refs/notes/", ref)
+	}
+
+	reference, err := r.repo.Reference(plumbing.ReferenceName(ref), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	commit, err := r.repo.CommitObject(reference.Hash())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get notes commit: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get notes tree: %w", err)
+	}
+
+	return tree, reference, nil
+}
+
+// fanoutPath returns the path of a new note of hash: under the fanout
+// directories tree has for it, e.g. ab/cdef..., or at its root.
+func (r *gitRepository) fanoutPath(tree *object.Tree, hash string) string {
+	var dirs []string
+	for tree != nil && len(hash) > 2 {
+		var subtree *object.Tree
+		for _, entry := range tree.Entries {
+			if entry.Name == hash[:2] && entry.Mode == filemode.Dir {
+				subtree, _ = r.repo.TreeObject(entry.Hash)
+				break
+			}
+		}
+		if subtree == nil {
+			break
+		}
+		dirs = append(dirs, hash[:2])
+		hash = hash[2:]
+		tree = subtree
+	}
+
+	return path.Join(append(dirs, hash)...)
+}
+
+// updateTree writes tree with the blobs in files added or replaced, keyed by
+// their path in it, and returns its hash. A nil tree is empty.
+func (r *gitRepository) updateTree(tree *object.Tree, files map[string]plumbing.Hash) (plumbing.Hash, error) {
+	entries := make(map[string]object.TreeEntry)
+	if tree != nil {
+		for _, entry := range tree.Entries {
+			entries[entry.Name] = entry
+		}
+	}
+
+	subdirs := make(map[string]map[string]plumbing.Hash)
+	for filePath, blob := range files {
+		dir, rest, nested := strings.Cut(filePath, "/")
+		if !nested {
+			entries[filePath] = object.TreeEntry{Name: filePath, Mode: filemode.Regular, Hash: blob}
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]plumbing.Hash)
+		}
+		subdirs[dir][rest] = blob
+	}
+
+	for dir, subfiles := range subdirs {
+		var subtree *object.Tree
+		if entry, ok := entries[dir]; ok && entry.Mode == filemode.Dir {
+			var err error
+			if subtree, err = r.repo.TreeObject(entry.Hash); err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to get tree %s: %w", dir, err)
+			}
+		}
+		hash, err := r.updateTree(subtree, subfiles)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries[dir] = object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash}
+	}
+
+	sorted := make([]object.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, entry)
+	}
+	// Git sorts directories as if their name ended with a slash.
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sortName(sorted[i]) < sortName(sorted[j]) })
+
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: sorted}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+// createReference sets ref if it does not exist yet and fails otherwise.
+// CheckAndSetReference cannot do that: without an old value it overwrites
+// the ref.
+func (r *gitRepository) createReference(ref *plumbing.Reference) error {
+	_, err := r.repo.Storer.Reference(ref.Name())
+	if err == nil {
+		return storage.ErrReferenceHasChanged
+	}
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	fsStorage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return r.repo.Storer.SetReference(ref)
+	}
+
+	// Creating the loose ref exclusively fails if another writer created it
+	// since it was looked up.
+	f, err := fsStorage.Filesystem().OpenFile(ref.Name().String(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if errors.Is(err, os.ErrExist) {
+		return storage.ErrReferenceHasChanged
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, ref.Hash().String()); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// notedCommit returns the commit a notes tree path belongs to. Paths may be
+// split into fanout directories, e.g. ab/cdef....
+func notedCommit(notePath string) (string, bool) {
+	hash := strings.ReplaceAll(notePath, "/", "")
+	if len(hash) != 40 || strings.Trim(hash, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return hash, true
+}
+
+func (r *gitRepository) writeObject(t plumbing.ObjectType, content []byte) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(t)
+	obj.SetSize(int64(len(content)))
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+// identity is the configured user, like git uses for commits.
+func (r *gitRepository) identity() object.Signature {
+	signature := notesIdentity
+	if cfg, err := r.repo.ConfigScoped(config.GlobalScope); err == nil && cfg.User.Name != "" && cfg.User.Email != "" {
+		signature = object.Signature{Name: cfg.User.Name, Email: cfg.User.Email}
+	}
+	signature.When = time.Now()
+
+	return signature
+}
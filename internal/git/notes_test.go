@@ -0,0 +1,226 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+func TestGitRepository_Notes(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tmpDir := initTestRepo(t)
+	commitTestFile(t, tmpDir, "a.go", numberedLines("a", 10), "Add a", base)
+	first := strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", "HEAD"))
+	commitTestFile(t, tmpDir, "b.go", numberedLines("b", 10), "Add b", base.Add(time.Minute))
+	second := strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", "HEAD"))
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*gitRepository)
+
+	notes, err := r.ReadNotes(DefaultNotesRef)
+	if err != nil {
+		t.Fatalf("ReadNotes() unexpected error = %v", err)
+	}
+	if len(notes) != 0 {
+		t.Fatalf("ReadNotes() = %v, want none before annotating", notes)
+	}
+
+	if err := r.WriteNotes(DefaultNotesRef, map[string]string{first: "first note\n"}, "Annotate"); err != nil {
+		t.Fatalf("WriteNotes() unexpected error = %v", err)
+	}
+
+	t.Run("readable by git", func(t *testing.T) {
+		if out := runGit(t, tmpDir, nil, "notes", "--ref=vibector", "show", first); out != "first note\n" {
+			t.Errorf("git notes show = %q, want the written note", out)
+		}
+		if out := runGit(t, tmpDir, nil, "log", "--notes=vibector", "--format=%N", "-1", first); !strings.Contains(out, "first note") {
+			t.Errorf("git log --notes = %q, want the written note", out)
+		}
+		runGit(t, tmpDir, nil, "fsck", "--strict")
+	})
+
+	t.Run("keeps notes added by git", func(t *testing.T) {
+		runGit(t, tmpDir, nil, "notes", "--ref=vibector", "append", "-m", "Reviewed-by: Jane", first)
+
+		if err := r.WriteNotes(DefaultNotesRef, map[string]string{second: "second note\n"}, "Annotate"); err != nil {
+			t.Fatalf("WriteNotes() unexpected error = %v", err)
+		}
+
+		notes, err := r.ReadNotes(DefaultNotesRef)
+		if err != nil {
+			t.Fatalf("ReadNotes() unexpected error = %v", err)
+		}
+		want := map[string]string{
+			first:  "first note\n\nReviewed-by: Jane\n",
+			second: "second note\n",
+		}
+		if len(notes) != len(want) || notes[first] != want[first] || notes[second] != want[second] {
+			t.Errorf("ReadNotes() = %q, want %q", notes, want)
+		}
+
+		if out := runGit(t, tmpDir, nil, "rev-list", "--count", DefaultNotesRef); strings.TrimSpace(out) != "3" {
+			t.Errorf("notes ref has %s commits, want 3", strings.TrimSpace(out))
+		}
+	})
+
+	t.Run("replaces a note", func(t *testing.T) {
+		if err := r.WriteNotes(DefaultNotesRef, map[string]string{second: "replaced\n"}, "Annotate"); err != nil {
+			t.Fatalf("WriteNotes() unexpected error = %v", err)
+		}
+		if out := runGit(t, tmpDir, nil, "notes", "--ref=vibector", "show", second); out != "replaced\n" {
+			t.Errorf("git notes show = %q, want the replaced note", out)
+		}
+	})
+
+	t.Run("other notes ref", func(t *testing.T) {
+		notes, err := r.ReadNotes("refs/notes/commits")
+		if err != nil {
+			t.Fatalf("ReadNotes() unexpected error = %v", err)
+		}
+		if len(notes) != 0 {
+			t.Errorf("ReadNotes() = %v, want none", notes)
+		}
+	})
+
+	t.Run("invalid ref", func(t *testing.T) {
+		if _, err := r.ReadNotes("refs/heads/main"); err == nil {
+			t.Error("ReadNotes() expected error, got nil")
+		}
+		if err := r.WriteNotes("vibector", map[string]string{first: "note"}, "Annotate"); err == nil {
+			t.Error("WriteNotes() expected error, got nil")
+		}
+	})
+}
+
+func TestGitRepository_WriteNotesFanout(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tmpDir := initTestRepo(t)
+	var commits []string
+	for i, name := range []string{"a.go", "b.go", "c.go"} {
+		commitTestFile(t, tmpDir, name, numberedLines(name, 10), "Add "+name, base.Add(time.Duration(i)*time.Minute))
+		commits = append(commits, strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", "HEAD")))
+	}
+	first, second, third := commits[0], commits[1], commits[2]
+
+	// Build a notes tree the way git does for many notes: split into fanout
+	// directories, next to a file that is not a note.
+	blob := func(content string) string {
+		file := filepath.Join(t.TempDir(), "blob")
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write blob: %v", err)
+		}
+		return strings.TrimSpace(runGit(t, tmpDir, nil, "hash-object", "-w", file))
+	}
+	index := []string{"GIT_INDEX_FILE=" + filepath.Join(t.TempDir(), "index")}
+	for notePath, content := range map[string]string{
+		first[:2] + "/" + first[2:]:   "first note\n",
+		second[:2] + "/" + second[2:]: "second note\n",
+		"README":                      "not a note\n",
+	} {
+		runGit(t, tmpDir, index, "update-index", "--add", "--cacheinfo", "100644,"+blob(content)+","+notePath)
+	}
+	tree := strings.TrimSpace(runGit(t, tmpDir, index, "write-tree"))
+	notesCommit := strings.TrimSpace(runGit(t, tmpDir, nil, "commit-tree", tree, "-m", "Notes"))
+	runGit(t, tmpDir, nil, "update-ref", DefaultNotesRef, notesCommit)
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*gitRepository)
+
+	notes, err := r.ReadNotes(DefaultNotesRef)
+	if err != nil {
+		t.Fatalf("ReadNotes() unexpected error = %v", err)
+	}
+	if len(notes) != 2 || notes[first] != "first note\n" || notes[second] != "second note\n" {
+		t.Errorf("ReadNotes() = %q, want the fanned-out notes", notes)
+	}
+
+	if err := r.WriteNotes(DefaultNotesRef, map[string]string{second: "replaced\n", third: "third note\n"}, "Annotate"); err != nil {
+		t.Fatalf("WriteNotes() unexpected error = %v", err)
+	}
+
+	for commit, want := range map[string]string{first: "first note\n", second: "replaced\n", third: "third note\n"} {
+		if out := runGit(t, tmpDir, nil, "notes", "--ref=vibector", "show", commit); out != want {
+			t.Errorf("git notes show %s = %q, want %q", commit, out, want)
+		}
+	}
+	if out := runGit(t, tmpDir, nil, "cat-file", "-p", DefaultNotesRef+":README"); out != "not a note\n" {
+		t.Errorf("README = %q, want it kept", out)
+	}
+	files := strings.Fields(runGit(t, tmpDir, nil, "ls-tree", "-r", "--name-only", DefaultNotesRef))
+	if len(files) != 4 || !slices.Contains(files, second[:2]+"/"+second[2:]) {
+		t.Errorf("notes tree = %v, want the replaced note in place and no duplicates", files)
+	}
+	runGit(t, tmpDir, nil, "fsck", "--strict")
+}
+
+func TestGitRepository_CreateReference(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	commitTestFile(t, tmpDir, "a.go", numberedLines("a", 10), "Add a", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	head := strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", "HEAD"))
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*gitRepository)
+
+	ref := plumbing.NewHashReference(DefaultNotesRef, plumbing.NewHash(head))
+	if err := r.createReference(ref); err != nil {
+		t.Fatalf("createReference() unexpected error = %v", err)
+	}
+	if out := strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", DefaultNotesRef)); out != head {
+		t.Errorf("%s = %s, want %s", DefaultNotesRef, out, head)
+	}
+
+	// A notes ref created by someone else since it was read is not replaced.
+	notesCommit := strings.TrimSpace(runGit(t, tmpDir, nil, "commit-tree", "HEAD^{tree}", "-m", "Notes"))
+	runGit(t, tmpDir, nil, "update-ref", "refs/notes/other", notesCommit)
+	other := plumbing.NewHashReference("refs/notes/other", plumbing.NewHash(head))
+	if err := r.createReference(other); !errors.Is(err, storage.ErrReferenceHasChanged) {
+		t.Errorf("createReference() error = %v, want %v", err, storage.ErrReferenceHasChanged)
+	}
+	if out := strings.TrimSpace(runGit(t, tmpDir, nil, "rev-parse", "refs/notes/other")); out != notesCommit {
+		t.Errorf("refs/notes/other = %s, want it unchanged at %s", out, notesCommit)
+	}
+}
+
+func TestNotedCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		notePath string
+		want     string
+		wantOK   bool
+	}{
+		{"flat", "0123456789abcdef0123456789abcdef01234567", "0123456789abcdef0123456789abcdef01234567", true},
+		{"fanout", "01/23456789abcdef0123456789abcdef01234567", "0123456789abcdef0123456789abcdef01234567", true},
+		{"short", "0123456789abcdef", "", false},
+		{"not hex", "0123456789abcdef0123456789abcdef0123456z", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := notedCommit(tt.notePath)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("notedCommit(%q) = (%q, %v), want (%q, %v)", tt.notePath, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+type PendingOptions struct {
+	// Staged diffs the index against HEAD, like git diff --cached. Otherwise
+	// the tracked files of the working tree are diffed, like git diff HEAD.
+	Staged bool
+
+	// TimeSource selects the clock of HEAD the time since the last commit is
+	// measured from.
+	TimeSource TimeSource
+
+	// Now is the time the pending change is considered committed at. Zero
+	// uses the current time.
+	Now time.Time
+}
+
+// GetPendingChanges returns the uncommitted changes as a pair of HEAD and a
+// commit that does not exist yet, timestamped opts.Now. It returns nil when
+// HEAD has no commits, as root commits are never paired.
+func (r *gitRepository) GetPendingChanges(opts *PendingOptions) (*CommitPair, error) {
+	if opts == nil {
+		opts = &PendingOptions{}
+	}
+
+	timeSource, err := resolveTimeSource(opts.TimeSource)
+	if err != nil {
+		return nil, err
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	wt, err := r.repo.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) {
+		return nil, fmt.Errorf("bare repository has no pending changes to check")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	head, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	headCommit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of HEAD: %w", err)
+	}
+
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	// Trees and working tree blobs of the pending change are only kept in
+	// memory, on top of the repository's objects.
+	s := &overlayStorage{Storer: r.repo.Storer, pending: memory.NewStorage()}
+
+	root := newPendingTree()
+	for _, e := range idx.Entries {
+		// Unmerged paths have entries in stages 1 to 3. Merged ones are
+		// read as stage 0, despite index.Merged.
+		if e.Stage != 0 || (opts.Staged && e.IntentToAdd) {
+			continue
+		}
+
+		// Entries outside a sparse checkout are not in the working tree, but
+		// they are not deleted either: keep their index blob.
+		hash, mode, ok := e.Hash, e.Mode, true
+		if !opts.Staged && e.Mode != filemode.Submodule && !e.SkipWorktree {
+			hash, ok, err = worktreeBlob(wt, e, s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			root.add(e.Name, mode, hash)
+		}
+	}
+
+	treeHash, err := root.write(s)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay, err := git.Open(s, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pending objects: %w", err)
+	}
+
+	pendingTree, err := object.GetTree(s, treeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending tree: %w", err)
+	}
+
+	pending := *r
+	pending.repo = overlay
+	stats, err := pending.treeDiffStats(headTree, pendingTree)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := r.newCommit(headCommit, timeSource)
+	return &CommitPair{
+		Previous:  previous,
+		Current:   &Commit{Timestamp: now, Parents: []string{previous.Hash}, timeSource: timeSource},
+		TimeDelta: now.Sub(previous.Timestamp),
+		Stats:     stats,
+	}, nil
+}
+
+// worktreeBlob stores the working tree content of a tracked file and returns
+// its hash, or false when the file was deleted.
+func worktreeBlob(wt *git.Worktree, e *index.Entry, s *overlayStorage) (plumbing.Hash, bool, error) {
+	fi, err := wt.Filesystem.Lstat(e.Name)
+	if os.IsNotExist(err) {
+		return plumbing.ZeroHash, false, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to stat %s: %w", e.Name, err)
+	}
+
+	// Like git, trust the index for files whose size and mtime are unchanged.
+	if fi.Mode().IsRegular() && fi.Size() == int64(e.Size) && fi.ModTime().Equal(e.ModifiedAt) {
+		return e.Hash, true, nil
+	}
+
+	var content []byte
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := wt.Filesystem.Readlink(e.Name)
+		if err != nil {
+			return plumbing.ZeroHash, false, fmt.Errorf("failed to read link %s: %w", e.Name, err)
+		}
+		content = []byte(target)
+	} else {
+		f, err := wt.Filesystem.Open(e.Name)
+		if err != nil {
+			return plumbing.ZeroHash, false, fmt.Errorf("failed to open %s: %w", e.Name, err)
+		}
+		content, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return plumbing.ZeroHash, false, fmt.Errorf("failed to read %s: %w", e.Name, err)
+		}
+	}
+
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to write blob of %s: %w", e.Name, err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to write blob of %s: %w", e.Name, err)
+	}
+	w.Close()
+
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to write blob of %s: %w", e.Name, err)
+	}
+
+	return hash, true, nil
+}
+
+// pendingTree builds tree objects from the flat paths of the index.
+type pendingTree struct {
+	files []object.TreeEntry
+	dirs  map[string]*pendingTree
+}
+
+func newPendingTree() *pendingTree {
+	return &pendingTree{dirs: make(map[string]*pendingTree)}
+}
+
+func (t *pendingTree) add(filePath string, mode filemode.FileMode, hash plumbing.Hash) {
+	dir, rest, nested := strings.Cut(filePath, "/")
+	if !nested {
+		t.files = append(t.files, object.TreeEntry{Name: filePath, Mode: mode, Hash: hash})
+		return
+	}
+
+	sub, ok := t.dirs[dir]
+	if !ok {
+		sub = newPendingTree()
+		t.dirs[dir] = sub
+	}
+	sub.add(rest, mode, hash)
+}
+
+func (t *pendingTree) write(s *overlayStorage) (plumbing.Hash, error) {
+	entries := append([]object.TreeEntry(nil), t.files...)
+	for name, sub := range t.dirs {
+		hash, err := sub.write(s)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders directories as if their name ended with a slash.
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool { return sortKey(entries[i]) < sortKey(entries[j]) })
+
+	obj := s.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write tree: %w", err)
+	}
+
+	return hash, nil
+}
+
+// overlayStorage keeps new objects in memory and reads everything else from
+// the repository, which is never written to.
+type overlayStorage struct {
+	storage.Storer
+	pending *memory.Storage
+}
+
+func (s *overlayStorage) NewEncodedObject() plumbing.EncodedObject {
+	return s.pending.NewEncodedObject()
+}
+
+func (s *overlayStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	return s.pending.SetEncodedObject(obj)
+}
+
+func (s *overlayStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.pending.EncodedObject(t, h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.Storer.EncodedObject(t, h)
+	}
+	return obj, err
+}
+
+func (s *overlayStorage) HasEncodedObject(h plumbing.Hash) error {
+	if err := s.pending.HasEncodedObject(h); err == nil {
+		return nil
+	}
+	return s.Storer.HasEncodedObject(h)
+}
+
+func (s *overlayStorage) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	size, err := s.pending.EncodedObjectSize(h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.Storer.EncodedObjectSize(h)
+	}
+	return size, err
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGitRepository_GetPendingChanges(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "main.go", numberedLines("main", 10), "Add main", base)
+	commitTestFile(t, tmpDir, "docs/old.md", numberedLines("old", 4), "Add docs", base.Add(time.Minute))
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// Staged: a new nested file and the deletion of docs/old.md. Unstaged on
+	// top: 5 more lines in main.go and a further edit of the staged file.
+	writeFile("pkg/util/util.go", numberedLines("util", 20))
+	runGit(t, tmpDir, nil, "add", "pkg/util/util.go")
+	runGit(t, tmpDir, nil, "rm", "-q", "docs/old.md")
+	writeFile("main.go", numberedLines("main", 15))
+	writeFile("pkg/util/util.go", numberedLines("util", 30))
+	writeFile("untracked.txt", numberedLines("untracked", 50))
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+	gitRepo := repo.(*gitRepository)
+
+	tests := []struct {
+		name          string
+		staged        bool
+		wantAdditions int64
+		wantDeletions int64
+		wantFiles     int
+	}{
+		{"staged changes", true, 20, 4, 2},
+		{"working tree changes", false, 35, 4, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := base.Add(11 * time.Minute)
+			pair, err := gitRepo.GetPendingChanges(&PendingOptions{Staged: tt.staged, Now: now})
+			if err != nil {
+				t.Fatalf("GetPendingChanges() unexpected error = %v", err)
+			}
+
+			if pair.Previous.Message != "Add docs\n" {
+				t.Errorf("Previous = %q, want HEAD", pair.Previous.Message)
+			}
+			if pair.TimeDelta != 10*time.Minute {
+				t.Errorf("TimeDelta = %v, want 10m", pair.TimeDelta)
+			}
+			if !pair.Current.Timestamp.Equal(now) {
+				t.Errorf("Current.Timestamp = %v, want %v", pair.Current.Timestamp, now)
+			}
+			if pair.Stats.Additions != tt.wantAdditions || pair.Stats.Deletions != tt.wantDeletions || pair.Stats.FilesChanged != tt.wantFiles {
+				t.Errorf("Stats = +%d -%d in %d files, want +%d -%d in %d files",
+					pair.Stats.Additions, pair.Stats.Deletions, pair.Stats.FilesChanged,
+					tt.wantAdditions, tt.wantDeletions, tt.wantFiles)
+			}
+		})
+	}
+
+	t.Run("repository left untouched", func(t *testing.T) {
+		if got := runGit(t, tmpDir, nil, "diff", "--cached", "--name-status"); got != "D\tdocs/old.md\nA\tpkg/util/util.go\n" {
+			t.Errorf("index changed: %q", got)
+		}
+		if out := runGit(t, tmpDir, nil, "fsck", "--no-dangling"); out != "" {
+			t.Errorf("git fsck: %s", out)
+		}
+	})
+
+	t.Run("no pending changes", func(t *testing.T) {
+		clean := initTestRepo(t)
+		commitTestFile(t, clean, "main.go", numberedLines("main", 10), "Add main", base)
+
+		cleanRepo, err := OpenRepository(clean, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer cleanRepo.Close()
+
+		pair, err := cleanRepo.(*gitRepository).GetPendingChanges(nil)
+		if err != nil {
+			t.Fatalf("GetPendingChanges() unexpected error = %v", err)
+		}
+		if pair.Stats.TotalAdditions != 0 || pair.Stats.TotalDeletions != 0 || len(pair.Stats.Files) != 0 {
+			t.Errorf("Stats = %+v, want no changes", pair.Stats)
+		}
+	})
+
+	t.Run("sparse checkout", func(t *testing.T) {
+		sparse := initTestRepo(t)
+		commitTestFile(t, sparse, "main.go", numberedLines("main", 10), "Add main", base)
+		commitTestFile(t, sparse, "docs/guide.md", numberedLines("guide", 8), "Add guide", base.Add(time.Minute))
+		runGit(t, sparse, nil, "update-index", "--skip-worktree", "docs/guide.md")
+		if err := os.Remove(filepath.Join(sparse, "docs", "guide.md")); err != nil {
+			t.Fatalf("Failed to remove guide.md: %v", err)
+		}
+
+		sparseRepo, err := OpenRepository(sparse, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer sparseRepo.Close()
+
+		pair, err := sparseRepo.(*gitRepository).GetPendingChanges(nil)
+		if err != nil {
+			t.Fatalf("GetPendingChanges() unexpected error = %v", err)
+		}
+		if pair.Stats.TotalDeletions != 0 || len(pair.Stats.Files) != 0 {
+			t.Errorf("Stats = %+v, want files outside the sparse checkout unchanged", pair.Stats)
+		}
+	})
+
+	t.Run("unknown time source", func(t *testing.T) {
+		if _, err := gitRepo.GetPendingChanges(&PendingOptions{TimeSource: "commit"}); err == nil {
+			t.Error("GetPendingChanges() expected error, got nil")
+		}
+	})
+
+	t.Run("no commits yet", func(t *testing.T) {
+		empty := initTestRepo(t)
+
+		emptyRepo, err := OpenRepository(empty, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer emptyRepo.Close()
+
+		pair, err := emptyRepo.(*gitRepository).GetPendingChanges(&PendingOptions{Staged: true})
+		if err != nil {
+			t.Fatalf("GetPendingChanges() unexpected error = %v", err)
+		}
+		if pair != nil {
+			t.Errorf("GetPendingChanges() = %+v, want nil", pair)
+		}
+	})
+}
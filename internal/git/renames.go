@@ -0,0 +1,192 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultRenameSimilarity matches git's default for -M and -C: files that
+// share at least half of their content are paired.
+const DefaultRenameSimilarity = 50
+
+// renameLimit mirrors git's diff.renameLimit: above that many added or
+// deleted files only exact renames are detected. Copies use the same limit
+// for added and modified files.
+const renameLimit = 1000
+
+// diffTrees diffs two trees with rename detection unless it is disabled.
+func (r *gitRepository) diffTrees(fromTree, toTree *object.Tree) (object.Changes, error) {
+	if r.renameSimilarity == 0 {
+		changes, err := object.DiffTree(fromTree, toTree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff: %w", err)
+		}
+		return changes, nil
+	}
+
+	changes, err := object.DiffTreeWithOptions(context.Background(), fromTree, toTree, &object.DiffTreeOptions{
+		DetectRenames: true,
+		RenameScore:   uint(r.renameSimilarity),
+		RenameLimit:   renameLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	return changes, nil
+}
+
+// detectCopies turns added files that copy a file modified in the same
+// change set into copies of it, like git diff -C. The returned set holds the
+// indexes of those changes; their patches then only contain the edits made
+// to the copy. Above renameLimit added or modified files, only exact copies
+// are detected, without reading any content.
+func (r *gitRepository) detectCopies(changes object.Changes, fromTree *object.Tree) (map[int]bool, error) {
+	if r.renameSimilarity == 0 {
+		return nil, nil
+	}
+
+	var sources []*object.Change
+	var added []int
+	for i, change := range changes {
+		if isSubmoduleChange(change) {
+			continue
+		}
+		switch {
+		case change.From.Name != "" && change.From.Name == change.To.Name:
+			sources = append(sources, change)
+		case change.From.Name == "" && change.To.Name != "":
+			added = append(added, i)
+		}
+	}
+	if len(sources) == 0 || len(added) == 0 {
+		return nil, nil
+	}
+
+	if max(len(sources), len(added)) > renameLimit {
+		return exactCopies(changes, sources, added), nil
+	}
+
+	sourceContents := make([]string, len(sources))
+	for i, src := range sources {
+		content, ok, err := changeEntryContent(fromTree, &src.From)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sourceContents[i] = content
+		}
+	}
+
+	copies := make(map[int]bool)
+	for _, i := range added {
+		change := changes[i]
+		content, ok, err := changeEntryContent(change.To.Tree, &change.To)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || content == "" {
+			continue
+		}
+
+		best, bestScore := -1, r.renameSimilarity-1
+		for j, src := range sources {
+			var score int
+			if src.From.TreeEntry.Hash == change.To.TreeEntry.Hash {
+				score = 100
+			} else {
+				score = similarity(sourceContents[j], content)
+			}
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		changes[i] = &object.Change{From: sources[best].From, To: change.To}
+		copies[i] = true
+	}
+
+	return copies, nil
+}
+
+// exactCopies pairs added files with a modified file whose previous content
+// they match byte for byte.
+func exactCopies(changes object.Changes, sources []*object.Change, added []int) map[int]bool {
+	byHash := make(map[plumbing.Hash]*object.Change, len(sources))
+	for _, src := range sources {
+		if _, ok := byHash[src.From.TreeEntry.Hash]; !ok {
+			byHash[src.From.TreeEntry.Hash] = src
+		}
+	}
+
+	copies := make(map[int]bool)
+	for _, i := range added {
+		src, ok := byHash[changes[i].To.TreeEntry.Hash]
+		if !ok {
+			continue
+		}
+		changes[i] = &object.Change{From: src.From, To: changes[i].To}
+		copies[i] = true
+	}
+
+	return copies
+}
+
+// changeEntryContent returns the content of a text blob; ok is false for
+// binary files, which are never paired.
+func changeEntryContent(tree *object.Tree, entry *object.ChangeEntry) (string, bool, error) {
+	f, err := tree.TreeEntryFile(&entry.TreeEntry)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get file %s: %w", entry.Name, err)
+	}
+
+	binary, err := f.IsBinary()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file %s: %w", entry.Name, err)
+	}
+	if binary {
+		return "", false, nil
+	}
+
+	content, err := f.Contents()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file %s: %w", entry.Name, err)
+	}
+
+	return content, true, nil
+}
+
+// similarity scores from 0 to 100 how much of the larger of two texts is made
+// of lines they share.
+func similarity(a, b string) int {
+	larger := max(len(a), len(b))
+	if larger == 0 {
+		return 100
+	}
+
+	counts := make(map[string]int)
+	for _, line := range splitLines(a) {
+		counts[line]++
+	}
+
+	shared := 0
+	for _, line := range splitLines(b) {
+		if counts[line] > 0 {
+			counts[line]--
+			shared += len(line) + 1
+		}
+	}
+
+	return min(shared*100/larger, 100)
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func numberedLines(prefix string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString(fmt.Sprintf("%s line %d\n", prefix, i))
+	}
+	return sb.String()
+}
+
+func TestGetCommitPairs_Renames(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	big := numberedLines("big", 100)
+	util := numberedLines("util", 100)
+	commitTestFile(t, tmpDir, "big.go", big, "Initial commit", base)
+	commitTestFile(t, tmpDir, "util.go", util, "Add util", base.Add(time.Hour))
+
+	// Move big.go and append a line in the same commit.
+	if err := os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0o750); err != nil {
+		t.Fatalf("Failed to create pkg: %v", err)
+	}
+	runGit(t, tmpDir, nil, "mv", "big.go", "pkg/big.go")
+	commitTestFile(t, tmpDir, "pkg/big.go", big+"appended\n", "Move big", base.Add(2*time.Hour))
+
+	// Copy util.go with one edited line while also modifying util.go.
+	if err := os.WriteFile(filepath.Join(tmpDir, "util_copy.go"), []byte(strings.Replace(util, "util line 5\n", "copy line 5\n", 1)), 0o600); err != nil {
+		t.Fatalf("Failed to write util_copy.go: %v", err)
+	}
+	runGit(t, tmpDir, nil, "add", "util_copy.go")
+	commitTestFile(t, tmpDir, "util.go", util+"extra\n", "Copy util", base.Add(3*time.Hour))
+
+	tests := []struct {
+		name string
+		opts *RepositoryOptions
+		want map[string]DiffStats
+	}{
+		{
+			name: "default similarity",
+			opts: nil,
+			want: map[string]DiffStats{
+				"Move big\n":  {Additions: 1, Deletions: 0, FilesChanged: 1, Renames: 1},
+				"Copy util\n": {Additions: 2, Deletions: 1, FilesChanged: 2, Copies: 1},
+			},
+		},
+		{
+			name: "renames disabled",
+			opts: &RepositoryOptions{DisableRenames: true},
+			want: map[string]DiffStats{
+				"Move big\n":  {Additions: 101, Deletions: 100, FilesChanged: 2},
+				"Copy util\n": {Additions: 101, Deletions: 0, FilesChanged: 2},
+			},
+		},
+		{
+			name: "similarity above actual overlap",
+			opts: &RepositoryOptions{RenameSimilarity: 100},
+			want: map[string]DiffStats{
+				"Move big\n":  {Additions: 101, Deletions: 100, FilesChanged: 2},
+				"Copy util\n": {Additions: 101, Deletions: 0, FilesChanged: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitRepo, err := OpenRepository(tmpDir, tt.opts)
+			if err != nil {
+				t.Fatalf("OpenRepository() unexpected error = %v", err)
+			}
+			defer gitRepo.Close()
+			repo := gitRepo.(*gitRepository)
+
+			commits, err := repo.GetCommits(nil)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			pairs, err := repo.GetCommitPairs(commits)
+			if err != nil {
+				t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+			}
+
+			for _, p := range pairs {
+				want, ok := tt.want[p.Current.Message]
+				if !ok {
+					continue
+				}
+				got := *p.Stats
+				if got.Additions != want.Additions || got.Deletions != want.Deletions ||
+					got.FilesChanged != want.FilesChanged || got.Renames != want.Renames || got.Copies != want.Copies {
+					t.Errorf("%q: got +%d -%d files=%d renames=%d copies=%d, want +%d -%d files=%d renames=%d copies=%d",
+						p.Current.Message, got.Additions, got.Deletions, got.FilesChanged, got.Renames, got.Copies,
+						want.Additions, want.Deletions, want.FilesChanged, want.Renames, want.Copies)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid similarity", func(t *testing.T) {
+		if _, err := OpenRepository(tmpDir, &RepositoryOptions{RenameSimilarity: 101}); err == nil {
+			t.Error("OpenRepository() should reject a similarity above 100")
+		}
+	})
+}
+
+func TestGetCommitPairs_CopyLimit(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	util := numberedLines("util", 100)
+	commitTestFile(t, tmpDir, "util.go", util, "Add util", base)
+
+	// Above renameLimit added files only the exact copy of util.go is found.
+	files := map[string]string{
+		"exact.go": util,
+		"near.go":  strings.Replace(util, "util line 5\n", "near line 5\n", 1),
+	}
+	for i := 0; i < renameLimit; i++ {
+		files[fmt.Sprintf("gen/file%d.txt", i)] = fmt.Sprintf("generated %d\n", i)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "gen"), 0o750); err != nil {
+		t.Fatalf("Failed to create gen: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	runGit(t, tmpDir, nil, "add", ".")
+	commitTestFile(t, tmpDir, "util.go", util+"extra\n", "Copy util", base.Add(time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+	if len(pairs) != 1 {
+		t.Fatalf("GetCommitPairs() returned %d pairs, want 1", len(pairs))
+	}
+
+	// util.go gains a line; near.go adds all its lines; exact.go adds none.
+	got := pairs[0].Stats
+	wantAdditions := int64(1 + 100 + renameLimit)
+	if got.Copies != 1 || got.Additions != wantAdditions || got.FilesChanged != renameLimit+3 {
+		t.Errorf("got +%d files=%d copies=%d, want +%d files=%d copies=1",
+			got.Additions, got.FilesChanged, got.Copies, wantAdditions, renameLimit+3)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 100},
+		{"disjoint", "a\nb\n", "c\nd\n", 0},
+		{"half shared", "aaa\nbbb\n", "aaa\nccc\n", 50},
+		{"reordered", "a\nb\n", "b\na\n", 100},
+		{"both empty", "", "", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("similarity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,87 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math/bits"
+	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/format/diff"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
+// ErrUnknownExcludedRevision is returned by GetCommits when one of
+// CommitOptions.ExcludeRevisions no longer resolves, e.g. after a force push
+// or garbage collection.
+var ErrUnknownExcludedRevision = errors.New("excluded revision not found")
+
 type RepositoryOptions struct {
-	ExcludeFiles []string
+	// ExcludeFiles holds gitignore-style patterns. They are applied after the
+	// patterns of the repository's .vibectorignore, so they take precedence.
+	ExcludeFiles  []string
+	IncludeMerges bool
+
+	// IncludePaths scopes the analysis to the given directories or files:
+	// only commits touching them are returned and only their changes count
+	// towards the filtered statistics.
+	IncludePaths []string
+
+	// RenameSimilarity is the percentage of shared content from which a
+	// deleted and an added file count as a rename, or an added file as a copy
+	// of a file modified in the same commit. Only the edits made on top are
+	// counted. Zero uses DefaultRenameSimilarity; DisableRenames turns
+	// detection off.
+	RenameSimilarity int
+	DisableRenames   bool
+
+	// IgnoreFormatting leaves formatting-only lines out of
+	// DiffStats.Additions and Deletions. They are always reported in
+	// DiffStats.FormattingAdditions and FormattingDeletions.
+	IgnoreFormatting bool
+
+	// Languages maps file extensions to language names, e.g. ".tmpl" to
+	// "Go", on top of the built-in classification used for
+	// DiffStats.Languages.
+	Languages map[string]string
+
+	// MailmapFile is an extra mailmap read after the repository's .mailmap.
+	// Author and committer identities of every commit are normalized with
+	// them.
+	MailmapFile string
+
+	// Keyring is a file of armored OpenPGP public keys and/or SSH allowed
+	// signers that commit signatures are verified against. Without it
+	// signatures are reported but not verified.
+	Keyring string
+
+	// Jobs is the number of diffs computed concurrently. Zero uses one per
+	// CPU.
+	Jobs int
+
+	// CacheDir is where DiffStats are persisted between runs, in a
+	// subdirectory per repository. Empty disables the cache.
+	CacheDir string
+
+	// GitDir is the git directory to open, like git --git-dir. The
+	// repository path is then its working tree, or empty when there is none.
+	GitDir string
+
+	// CloneDepth and CloneBranch only apply when the repository is given as a
+	// URL and cloned into memory. A zero depth fetches the full history.
+	CloneDepth  int
+	CloneBranch string
 }
 
 type Repository interface {
@@ -22,30 +90,245 @@ type Repository interface {
 }
 
 type gitRepository struct {
-	repo         *git.Repository
-	path         string
-	excludeFiles []string
+	repo          *git.Repository
+	path          string
+	location      string
+	excludes      *excludeMatcher
+	includeMerges bool
+	includePaths  []string
+
+	// renameSimilarity is the effective threshold, 0 when detection is off.
+	renameSimilarity int
+	ignoreFormatting bool
+	languages        *languageClassifier
+	mailmap          *mailmap
+	keyring          *keyring
+	jobs             int
+	cache            *diffCache
 }
 
-func OpenRepository(path string, opts *RepositoryOptions) (Repository, error) {
-	if path == "" {
+func OpenRepository(repoPath string, opts *RepositoryOptions) (Repository, error) {
+	if opts == nil {
+		opts = &RepositoryOptions{}
+	}
+
+	if repoPath == "" && opts.GitDir == "" {
 		return nil, fmt.Errorf("repository path cannot be empty")
 	}
 
-	if opts == nil {
-		opts = &RepositoryOptions{}
+	if opts.RenameSimilarity < 0 || opts.RenameSimilarity > 100 {
+		return nil, fmt.Errorf("rename similarity must be between 0 and 100, got %d", opts.RenameSimilarity)
+	}
+
+	renameSimilarity := opts.RenameSimilarity
+	if renameSimilarity == 0 {
+		renameSimilarity = DefaultRenameSimilarity
+	}
+	if opts.DisableRenames {
+		renameSimilarity = 0
+	}
+
+	if opts.Jobs < 0 {
+		return nil, fmt.Errorf("jobs cannot be negative, got %d", opts.Jobs)
 	}
 
-	r, err := git.PlainOpen(path)
+	jobs := opts.Jobs
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	var r *git.Repository
+	var err error
+
+	location := repoPath
+	switch {
+	case opts.GitDir != "":
+		if isRemoteURL(repoPath) {
+			return nil, fmt.Errorf("a git directory cannot be combined with a remote URL")
+		}
+		r, err = openGitDir(opts.GitDir, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		location = opts.GitDir
+	case isRemoteURL(repoPath):
+		r, err = cloneRepository(repoPath, opts)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		r, location, err = openLocalRepository(repoPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !isRemoteURL(location) {
+		if location, err = filepath.Abs(location); err != nil {
+			return nil, fmt.Errorf("failed to resolve git directory: %w", err)
+		}
+	}
+
+	ignored, err := loadIgnoreFile(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open local repository: %w", err)
+		return nil, err
+	}
+
+	mm, err := loadMailmap(r, opts.MailmapFile)
+	if err != nil {
+		return nil, err
+	}
+
+	kr, err := loadKeyring(opts.Keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &gitRepository{
+		repo:          r,
+		path:          repoPath,
+		location:      location,
+		excludes:      newExcludeMatcher(append(ignored, opts.ExcludeFiles...)),
+		includeMerges: opts.IncludeMerges,
+		includePaths:  normalizePaths(opts.IncludePaths),
+
+		renameSimilarity: renameSimilarity,
+		ignoreFormatting: opts.IgnoreFormatting,
+		languages:        newLanguageClassifier(opts.Languages),
+		mailmap:          mm,
+		keyring:          kr,
+		jobs:             jobs,
 	}
+	repo.cache = openDiffCache(opts.CacheDir, repositoryID(location), repo.fingerprint())
+
+	return repo, nil
+}
 
-	return &gitRepository{
-		repo:         r,
-		path:         path,
-		excludeFiles: opts.ExcludeFiles,
-	}, nil
+// Location returns the absolute git directory of a local repository, or the
+// URL of a cloned one. Unlike the path it was opened with, it is the same
+// whichever subdirectory or working tree the repository was opened from.
+func (r *gitRepository) Location() string {
+	return r.location
+}
+
+// Fingerprint identifies the settings the diff statistics depend on, such as
+// the exclude patterns of the options and of .vibectorignore.
+func (r *gitRepository) Fingerprint() string {
+	return r.fingerprint()
+}
+
+// isRemoteURL reports whether location is a URL (https://, ssh://, file://, ...)
+// or an scp-like address such as git@github.com:owner/repo.git.
+func isRemoteURL(location string) bool {
+	if strings.Contains(location, "://") {
+		return true
+	}
+
+	at := strings.Index(location, "@")
+	colon := strings.Index(location, ":")
+	return at > 0 && colon > at && !strings.ContainsAny(location[:at], `/\`)
+}
+
+// openLocalRepository opens the repository repoPath belongs to: a working
+// tree or any directory inside it, a linked worktree whose .git file points
+// into another repository, or a bare repository. It also returns the git
+// directory, which identifies the repository whatever directory it was opened
+// from.
+func openLocalRepository(repoPath string) (*git.Repository, string, error) {
+	if isGitDir(repoPath) {
+		r, err := openGitDir(repoPath, "")
+		if err != nil {
+			return nil, "", err
+		}
+		return r, repoPath, nil
+	}
+
+	r, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open local repository: %w", err)
+	}
+
+	location := repoPath
+	if s, ok := r.Storer.(*filesystem.Storage); ok {
+		location = s.Filesystem().Root()
+	}
+
+	return r, location, nil
+}
+
+// openGitDir opens the git directory gitDir with workTree as its working
+// tree. An empty workTree opens it like a bare repository.
+func openGitDir(gitDir, workTree string) (*git.Repository, error) {
+	if !isGitDir(gitDir) {
+		return nil, fmt.Errorf("not a git directory: %s", gitDir)
+	}
+
+	// Without DetectDotGit go-git uses a directory that has no .git entry as
+	// the git directory itself.
+	r, err := git.PlainOpenWithOptions(gitDir, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git directory %s: %w", gitDir, err)
+	}
+	if workTree == "" {
+		return r, nil
+	}
+
+	info, err := os.Stat(workTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open working tree: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("working tree %s is not a directory", workTree)
+	}
+
+	r, err = git.Open(r.Storer, osfs.New(workTree))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git directory %s: %w", gitDir, err)
+	}
+
+	return r, nil
+}
+
+// isGitDir reports whether dir looks like a git directory, i.e. a bare
+// repository or the .git directory of a working tree. Like git, it only
+// checks for HEAD, objects and refs.
+func isGitDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, name := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			// Linked worktrees keep objects and refs in their commondir.
+			if _, err := os.Stat(filepath.Join(dir, "commondir")); err != nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// cloneRepository clones url into go-git's in-memory storage without a
+// worktree, so nothing is written to disk and Close only has to drop it.
+func cloneRepository(url string, opts *RepositoryOptions) (*git.Repository, error) {
+	cloneOpts := &git.CloneOptions{
+		URL:   url,
+		Depth: opts.CloneDepth,
+	}
+	if opts.CloneBranch != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.CloneBranch)
+		cloneOpts.SingleBranch = true
+	}
+
+	r, err := git.Clone(memory.NewStorage(), nil, cloneOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository %s: %w", url, err)
+	}
+
+	return r, nil
 }
 
 func (r *gitRepository) GetCommits(opts *CommitOptions) ([]*Commit, error) {
@@ -53,111 +336,653 @@ func (r *gitRepository) GetCommits(opts *CommitOptions) ([]*Commit, error) {
 		opts = &CommitOptions{}
 	}
 
-	var ref *plumbing.Reference
-	var err error
+	timeSource, err := resolveTimeSource(opts.TimeSource)
+	if err != nil {
+		return nil, err
+	}
+
+	multiRef := opts.AllRefs || len(opts.RefPatterns) > 0
+
+	tips, err := r.startTips(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	if opts.Branch != "" {
-		ref, err = r.repo.Reference(plumbing.ReferenceName("refs/heads/"+opts.Branch), true)
+	exclude := make(map[plumbing.Hash]bool)
+	if opts.Range != "" {
+		from, _, err := parseRange(opts.Range)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get branch reference: %w", err)
+			return nil, err
 		}
-	} else {
-		ref, err = r.repo.Head()
+
+		if from != "" {
+			base, err := r.resolveRevision(from)
+			if err != nil {
+				return nil, err
+			}
+			exclude, err = r.reachableFrom(base)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, rev := range opts.ExcludeRevisions {
+		base, err := r.resolveRevision(rev)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+			return nil, fmt.Errorf("%w: %s", ErrUnknownExcludedRevision, rev)
+		}
+		if exclude[base] {
+			continue
+		}
+
+		reachable, err := r.reachableFrom(base)
+		if err != nil {
+			return nil, err
+		}
+		for h := range reachable {
+			exclude[h] = true
 		}
 	}
 
-	commitIter, err := r.repo.Log(&git.LogOptions{
-		From: ref.Hash(),
-	})
+	shallow, err := r.repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+	boundary := make(map[plumbing.Hash]bool, len(shallow))
+	for _, h := range shallow {
+		boundary[h] = true
+	}
+
+	stop, err := r.shallowParents()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create commit iterator: %w", err)
+		return nil, err
+	}
+	for h := range exclude {
+		stop[h] = true
 	}
-	defer commitIter.Close()
 
 	commits := make([]*Commit, 0)
-	count := 0
+	var visited []*object.Commit
 
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		if opts.MaxDepth > 0 && count >= opts.MaxDepth {
-			return io.EOF
+	for _, tip := range tips {
+		start, err := r.repo.CommitObject(tip.hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", tip.hash, err)
 		}
 
-		parents := make([]string, len(c.ParentHashes))
-		for i, p := range c.ParentHashes {
-			parents[i] = p.String()
+		iter := object.NewCommitIterCTime(start, stop, nil)
+		err = iter.ForEach(func(c *object.Commit) error {
+			if multiRef {
+				// Later tips stop where they reach history walked before;
+				// which refs contain a commit is worked out afterwards.
+				stop[c.Hash] = true
+				visited = append(visited, c)
+			}
+
+			commit := r.newCommit(c, timeSource)
+			commit.Shallow = boundary[c.Hash]
+			if !opts.Since.IsZero() && commit.Timestamp.Before(opts.Since) {
+				return nil
+			}
+			if !opts.Until.IsZero() && commit.Timestamp.After(opts.Until) {
+				return nil
+			}
+
+			if len(r.includePaths) > 0 {
+				touches, err := r.touchesIncludedPaths(c)
+				if err != nil {
+					return err
+				}
+				if !touches {
+					return nil
+				}
+			}
+
+			if !multiRef && opts.MaxDepth > 0 && len(commits) >= opts.MaxDepth {
+				return io.EOF
+			}
+
+			commits = append(commits, commit)
+			return nil
+		})
+		iter.Close()
+
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error iterating commits: %w", err)
+		}
+	}
+
+	if multiRef {
+		refs := containingRefs(tips, visited)
+		for _, commit := range commits {
+			commit.Refs = refs[plumbing.NewHash(commit.Hash)]
 		}
 
-		commits = append(commits, &Commit{
-			Hash:      c.Hash.String(),
-			Author:    c.Author.Name,
-			Email:     c.Author.Email,
-			Timestamp: c.Author.When,
-			Message:   c.Message,
-			Parents:   parents,
+		sort.SliceStable(commits, func(i, j int) bool {
+			return commits[i].Timestamp.After(commits[j].Timestamp)
 		})
+		if opts.MaxDepth > 0 && len(commits) > opts.MaxDepth {
+			commits = commits[:opts.MaxDepth]
+		}
+	}
 
-		count++
+	return commits, nil
+}
+
+type refTip struct {
+	name string
+	hash plumbing.Hash
+}
+
+// containingRefs returns the names of the tips each visited commit is
+// reachable from, in tip order. The set of tips is carried from every commit
+// to its parents in a single pass that handles children before parents.
+func containingRefs(tips []refTip, visited []*object.Commit) map[plumbing.Hash][]string {
+	index := make(map[plumbing.Hash]int, len(visited))
+	for i, c := range visited {
+		index[c.Hash] = i
+	}
+
+	// children counts the visited children of each commit not handled yet.
+	children := make([]int, len(visited))
+	for _, c := range visited {
+		for _, p := range c.ParentHashes {
+			if j, ok := index[p]; ok {
+				children[j]++
+			}
+		}
+	}
+
+	words := (len(tips) + 63) / 64
+	sets := make([][]uint64, len(visited))
+	for i := range sets {
+		sets[i] = make([]uint64, words)
+	}
+	for t, tip := range tips {
+		if i, ok := index[tip.hash]; ok {
+			sets[i][t/64] |= 1 << (t % 64)
+		}
+	}
+
+	queue := make([]int, 0, len(visited))
+	for i := range visited {
+		if children[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, p := range visited[i].ParentHashes {
+			j, ok := index[p]
+			if !ok {
+				continue
+			}
+			for w := range sets[j] {
+				sets[j][w] |= sets[i][w]
+			}
+			children[j]--
+			if children[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	refs := make(map[plumbing.Hash][]string, len(visited))
+	for i, c := range visited {
+		for w, word := range sets[i] {
+			for word != 0 {
+				t := w*64 + bits.TrailingZeros64(word)
+				refs[c.Hash] = append(refs[c.Hash], tips[t].name)
+				word &= word - 1
+			}
+		}
+	}
+
+	return refs
+}
+
+// ResolveTips returns the commits GetCommits starts walking from for opts,
+// keyed by ref name ("HEAD" or the branch when a single branch is walked).
+func (r *gitRepository) ResolveTips(opts *CommitOptions) (map[string]string, error) {
+	if opts == nil {
+		opts = &CommitOptions{}
+	}
+
+	tips, err := r.startTips(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(tips))
+	for _, tip := range tips {
+		result[tip.name] = tip.hash.String()
+	}
+
+	return result, nil
+}
+
+// startTips resolves the refs to walk: every matching ref in multi-ref mode,
+// otherwise the branch (or HEAD), replaced by the end of Range when given.
+func (r *gitRepository) startTips(opts *CommitOptions) ([]refTip, error) {
+	multiRef := opts.AllRefs || len(opts.RefPatterns) > 0
+	if multiRef && opts.Branch != "" {
+		return nil, fmt.Errorf("branch cannot be combined with all refs or ref patterns")
+	}
+
+	var to string
+	if opts.Range != "" {
+		var err error
+		if _, to, err = parseRange(opts.Range); err != nil {
+			return nil, err
+		}
+	}
+
+	if multiRef {
+		if to != "" {
+			return nil, fmt.Errorf("range end cannot be combined with all refs or ref patterns")
+		}
+		return r.matchingRefs(opts.RefPatterns)
+	}
+
+	if to != "" {
+		tip, err := r.resolveRevision(to)
+		if err != nil {
+			return nil, err
+		}
+		return []refTip{{name: to, hash: tip}}, nil
+	}
+
+	tip, err := r.branchTip(opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Branch
+	if name == "" {
+		name = "HEAD"
+	}
+
+	return []refTip{{name: name, hash: tip}}, nil
+}
+
+// matchingRefs lists branches, remote-tracking branches and tags pointing at
+// commits, optionally filtered by glob patterns matched against the full ref
+// name (refs/heads/feature/*) or its short form (feature/*).
+func (r *gitRepository) matchingRefs(patterns []string) ([]refTip, error) {
+	iter, err := r.repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	defer iter.Close()
+
+	tips := make([]refTip, 0)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := ref.Name()
+		if !name.IsBranch() && !name.IsRemote() && !name.IsTag() {
+			return nil
+		}
+
+		if len(patterns) > 0 && !matchesRefPattern(name, patterns) {
+			return nil
+		}
+
+		hash := ref.Hash()
+		if name.IsTag() {
+			tag, err := r.repo.TagObject(hash)
+			if err == nil {
+				c, err := tag.Commit()
+				if err != nil {
+					return nil
+				}
+				hash = c.Hash
+			}
+		}
+
+		if _, err := r.repo.CommitObject(hash); err != nil {
+			return nil
+		}
+
+		tips = append(tips, refTip{name: name.String(), hash: hash})
 		return nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+
+	sort.Slice(tips, func(i, j int) bool { return tips[i].name < tips[j].name })
 
-	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("error iterating commits: %w", err)
+	if len(tips) == 0 {
+		return nil, fmt.Errorf("no references match %v", patterns)
 	}
 
-	return commits, nil
+	return tips, nil
+}
+
+func matchesRefPattern(name plumbing.ReferenceName, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name.String()); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, name.Short()); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *gitRepository) branchTip(branch string) (plumbing.Hash, error) {
+	if branch == "" {
+		ref, err := r.repo.Head()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		return ref.Hash(), nil
+	}
+
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err == plumbing.ErrReferenceNotFound {
+		ref, err = r.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), true)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get branch reference: %w", err)
+	}
+
+	return ref.Hash(), nil
+}
+
+func (r *gitRepository) resolveRevision(rev string) (plumbing.Hash, error) {
+	h, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve revision %q: %w", rev, err)
+	}
+	return *h, nil
+}
+
+// parseRange splits a git-style "A..B" range. Either side may be empty:
+// "A.." means everything after A up to the analyzed branch, "..B" everything
+// up to B.
+func parseRange(rng string) (from, to string, err error) {
+	if strings.Contains(rng, "...") {
+		return "", "", fmt.Errorf("symmetric revision ranges are not supported: %s", rng)
+	}
+
+	from, to, ok := strings.Cut(rng, "..")
+	if !ok || (from == "" && to == "") {
+		return "", "", fmt.Errorf("invalid revision range %q, expected A..B", rng)
+	}
+
+	return from, to, nil
+}
+
+// reachableFrom collects every commit reachable from the given hash.
+func (r *gitRepository) reachableFrom(from plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	reachable := make(map[plumbing.Hash]bool)
+	err := r.walkCommits(from, nil, func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk commits from %s: %w", from, err)
+	}
+
+	return reachable, nil
+}
+
+// walkCommits visits the commits reachable from the given hash, newest first
+// by committer time, skipping excluded commits and their ancestors. The walk
+// ends at shallow boundaries instead of failing on parents that were never fetched.
+func (r *gitRepository) walkCommits(from plumbing.Hash, exclude map[plumbing.Hash]bool, fn func(*object.Commit) error) error {
+	start, err := r.repo.CommitObject(from)
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", from, err)
+	}
+
+	stop, err := r.shallowParents()
+	if err != nil {
+		return err
+	}
+	for h := range exclude {
+		stop[h] = true
+	}
+
+	iter := object.NewCommitIterCTime(start, stop, nil)
+	defer iter.Close()
+
+	return iter.ForEach(fn)
+}
+
+// shallowParents returns the parents of shallow boundary commits, which are
+// referenced but absent from the object store.
+func (r *gitRepository) shallowParents() (map[plumbing.Hash]bool, error) {
+	shallow, err := r.repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+
+	parents := make(map[plumbing.Hash]bool)
+	for _, h := range shallow {
+		c, err := r.repo.CommitObject(h)
+		if err != nil {
+			continue
+		}
+		for _, p := range c.ParentHashes {
+			parents[p] = true
+		}
+	}
+
+	return parents, nil
 }
 
 func (r *gitRepository) GetCommitPairs(commits []*Commit) ([]*CommitPair, error) {
-	if len(commits) < 2 {
+	if len(commits) == 0 {
 		return []*CommitPair{}, nil
 	}
 
-	pairs := make([]*CommitPair, 0)
+	byHash := make(map[string]*Commit, len(commits))
+	for _, c := range commits {
+		byHash[c.Hash] = c
+	}
 
-	for i := 0; i < len(commits)-1; i++ {
-		current := commits[i]
-		previous := commits[i+1]
+	candidates := make([]*CommitPair, 0)
 
-		if len(current.Parents) > 1 {
+	for _, current := range commits {
+		if len(current.Parents) == 0 || current.Shallow {
 			continue
 		}
-
-		timeDelta := current.Timestamp.Sub(previous.Timestamp)
-		if timeDelta <= 0 {
+		isMerge := len(current.Parents) > 1
+		if isMerge && !r.includeMerges {
 			continue
 		}
 
-		stats, err := r.getDiffStats(previous.Hash, current.Hash)
+		previous, err := r.lookupCommit(byHash, current.Parents[0], current.timeSource)
 		if err != nil {
 			continue
 		}
 
-		pairs = append(pairs, &CommitPair{
+		timeDelta := current.Timestamp.Sub(previous.Timestamp)
+		if timeDelta <= 0 {
+			continue
+		}
+
+		candidates = append(candidates, &CommitPair{
 			Previous:  previous,
 			Current:   current,
 			TimeDelta: timeDelta,
-			Stats:     stats,
+			IsMerge:   isMerge,
 		})
 	}
 
+	// Diffs are computed concurrently; each worker only fills in its own
+	// pairs, so the result keeps the order of commits.
+	computed := make([]bool, len(candidates))
+	runParallel(len(candidates), r.jobs, func(i int) {
+		pair := candidates[i]
+
+		if entry, ok := r.cache.get(pair); ok {
+			pair.Stats = entry.Stats
+			pair.MergeStats = entry.MergeStats
+			computed[i] = true
+			return
+		}
+
+		stats, err := r.getDiffStats(pair.Previous.Hash, pair.Current.Hash)
+		if err != nil {
+			return
+		}
+		pair.Stats = stats
+
+		if pair.IsMerge {
+			pair.MergeStats, err = r.getMergeStats(pair.Current.Parents, pair.Current.Hash)
+			if err != nil {
+				return
+			}
+		}
+
+		r.cache.put(pair)
+		computed[i] = true
+	})
+
+	// The cache only saves time, so failing to write it does not fail the
+	// analysis.
+	_ = r.cache.save()
+
+	pairs := make([]*CommitPair, 0, len(candidates))
+	for i, pair := range candidates {
+		if computed[i] {
+			pairs = append(pairs, pair)
+		}
+	}
+
 	return pairs, nil
 }
 
-func (r *gitRepository) shouldExcludeFile(filePath string) bool {
-	if len(r.excludeFiles) == 0 {
-		return false
+// runParallel calls fn for every index below n on up to jobs goroutines and
+// waits for all of them.
+func runParallel(n, jobs int, fn func(i int)) {
+	jobs = max(min(jobs, n), 1)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
 	}
 
-	for _, pattern := range r.excludeFiles {
-		matched, err := filepath.Match(pattern, filepath.Base(filePath))
-		if err == nil && matched {
-			return true
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// resolveTimeSource validates a time source, defaulting to the author time.
+func resolveTimeSource(timeSource TimeSource) (TimeSource, error) {
+	switch timeSource {
+	case "":
+		return TimeSourceAuthor, nil
+	case TimeSourceAuthor, TimeSourceCommitter:
+		return timeSource, nil
+	default:
+		return "", fmt.Errorf("unknown time source: %s", timeSource)
+	}
+}
+
+// lookupCommit returns the parent from the analyzed set when present and
+// falls back to the object store for parents outside of it (e.g. beyond MaxDepth).
+func (r *gitRepository) lookupCommit(known map[string]*Commit, hash string, timeSource TimeSource) (*Commit, error) {
+	if c, ok := known[hash]; ok {
+		return c, nil
+	}
+
+	c, err := r.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent commit %s: %w", hash, err)
+	}
+
+	return r.newCommit(c, timeSource), nil
+}
+
+// newCommit converts c, normalizing its identities with the mailmap and
+// verifying its signature against the keyring.
+func (r *gitRepository) newCommit(c *object.Commit, timeSource TimeSource) *Commit {
+	parents := make([]string, len(c.ParentHashes))
+	for i, p := range c.ParentHashes {
+		parents[i] = p.String()
+	}
+
+	timestamp := c.Author.When
+	if timeSource == TimeSourceCommitter {
+		timestamp = c.Committer.When
+	}
+
+	author, email := r.mailmap.resolve(c.Author.Name, c.Author.Email)
+	committer, committerEmail := r.mailmap.resolve(c.Committer.Name, c.Committer.Email)
+
+	return &Commit{
+		Hash:               c.Hash.String(),
+		Author:             author,
+		Email:              email,
+		Timestamp:          timestamp,
+		Message:            c.Message,
+		Trailers:           parseTrailers(c.Message),
+		Parents:            parents,
+		Committer:          committer,
+		CommitterEmail:     committerEmail,
+		AuthorTimestamp:    c.Author.When,
+		CommitterTimestamp: c.Committer.When,
+		Signature:          commitSignature(c, r.keyring),
+		timeSource:         timeSource,
+	}
+}
+
+// touchesIncludedPaths reports whether c changes anything under the include
+// paths compared to its first parent.
+func (r *gitRepository) touchesIncludedPaths(c *object.Commit) (bool, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return false, fmt.Errorf("failed to get tree of %s: %w", c.Hash, err)
+	}
+
+	var parentTree *object.Tree
+	if len(c.ParentHashes) > 0 {
+		parentTree, err = r.commitTree(c.ParentHashes[0].String())
+		if err != nil {
+			parentTree = nil
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %s: %w", c.Hash, err)
+	}
+
+	for _, change := range changes {
+		if r.inIncludedPaths(change.From.Name) || r.inIncludedPaths(change.To.Name) {
+			return true, nil
 		}
+	}
+
+	return false, nil
+}
 
-		matched, err = filepath.Match(pattern, filePath)
-		if err == nil && matched {
+func (r *gitRepository) inIncludedPaths(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+
+	for _, p := range r.includePaths {
+		if filePath == p || strings.HasPrefix(filePath, p+"/") {
 			return true
 		}
 	}
@@ -165,37 +990,97 @@ func (r *gitRepository) shouldExcludeFile(filePath string) bool {
 	return false
 }
 
-func (r *gitRepository) getDiffStats(fromHash, toHash string) (*DiffStats, error) {
-	fromCommit, err := r.repo.CommitObject(plumbing.NewHash(fromHash))
+// normalizePaths turns user supplied paths into slash separated paths
+// relative to the repository root. "." selects the whole repository.
+func normalizePaths(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.Trim(path.Clean(filepath.ToSlash(p)), "/")
+		if p == "." || p == "" {
+			return nil
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+func (r *gitRepository) shouldExcludeFile(filePath string) bool {
+	if len(r.includePaths) > 0 && !r.inIncludedPaths(filePath) {
+		return true
+	}
+
+	return r.excludes.Match(filePath)
+}
+
+func (r *gitRepository) commitTree(hash string) (*object.Tree, error) {
+	commit, err := r.repo.CommitObject(plumbing.NewHash(hash))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get from commit: %w", err)
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
 	}
 
-	toCommit, err := r.repo.CommitObject(plumbing.NewHash(toHash))
+	tree, err := commit.Tree()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get to commit: %w", err)
+		return nil, fmt.Errorf("failed to get tree of %s: %w", hash, err)
 	}
 
-	fromTree, err := fromCommit.Tree()
+	return tree, nil
+}
+
+func (r *gitRepository) getDiffStats(fromHash, toHash string) (*DiffStats, error) {
+	fromTree, err := r.commitTree(fromHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get from tree: %w", err)
 	}
 
-	toTree, err := toCommit.Tree()
+	toTree, err := r.commitTree(toHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get to tree: %w", err)
 	}
 
-	changes, err := fromTree.Diff(toTree)
+	return r.treeDiffStats(fromTree, toTree)
+}
+
+func (r *gitRepository) treeDiffStats(fromTree, toTree *object.Tree) (*DiffStats, error) {
+	changes, err := r.diffTrees(fromTree, toTree)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get diff: %w", err)
+		return nil, err
+	}
+
+	attrs, err := loadAttributes(toTree, changedPaths(changes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attributes: %w", err)
+	}
+
+	copies, err := r.detectCopies(changes, fromTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect copies: %w", err)
 	}
 
 	stats := &DiffStats{}
 	filesChanged := make(map[string]bool)
 	filesChangedTotal := make(map[string]bool)
 
-	for _, change := range changes {
+	for i, change := range changes {
+		if isSubmoduleChange(change) {
+			file := FileStats{Path: change.To.Name, Status: FileModified, Submodule: true}
+			switch {
+			case change.From.Name == "":
+				file.Status = FileAdded
+			case change.To.Name == "":
+				file.Status = FileDeleted
+				file.Path = change.From.Name
+			}
+			file.Excluded = r.shouldExcludeFile(file.Path) || attrs.Excluded(file.Path)
+
+			filesChangedTotal[file.Path] = true
+			if !file.Excluded {
+				filesChanged[file.Path] = true
+				stats.SubmoduleUpdates++
+			}
+			stats.Files = append(stats.Files, file)
+			continue
+		}
+
 		patch, err := change.Patch()
 		if err != nil {
 			continue
@@ -211,26 +1096,60 @@ func (r *gitRepository) getDiffStats(fromHash, toHash string) (*DiffStats, error
 				filePath = from.Path()
 			}
 
-			if from != nil {
-				filesChangedTotal[from.Path()] = true
-			}
-			if to != nil {
-				filesChangedTotal[to.Path()] = true
-			}
+			filesChangedTotal[filePath] = true
 
-			isExcluded := r.shouldExcludeFile(filePath)
+			isExcluded := r.shouldExcludeFile(filePath) || attrs.Excluded(filePath)
+
+			file := FileStats{Path: filePath, Status: FileModified, Language: r.languages.classify(filePath), Excluded: isExcluded}
+			switch {
+			case copies[i]:
+				file.Status = FileAdded
+				file.OldPath = from.Path()
+			case from == nil:
+				file.Status = FileAdded
+			case to == nil:
+				file.Status = FileDeleted
+			case from.Path() != to.Path():
+				file.OldPath = from.Path()
+			}
 
 			if !isExcluded {
-				if from != nil {
-					filesChanged[from.Path()] = true
+				filesChanged[filePath] = true
+
+				switch {
+				case copies[i]:
+					stats.Copies++
+				case from != nil && to != nil && from.Path() != to.Path():
+					stats.Renames++
 				}
-				if to != nil {
-					filesChanged[to.Path()] = true
+
+				if filePatch.IsBinary() {
+					delta, err := r.binarySizeDelta(from, to)
+					if err != nil {
+						return nil, err
+					}
+					stats.BinaryFiles++
+					stats.BinarySizeDelta += delta
 				}
 			}
 
+			// Formatting is only looked for within a hunk, the run of
+			// changes between two unchanged parts of the file.
+			var added, deleted []string
+			var formattingAdded, formattingDeleted int64
+			endHunk := func() {
+				a, d := formattingLines(added, deleted)
+				formattingAdded += a
+				formattingDeleted += d
+				added, deleted = nil, nil
+			}
+
 			chunks := filePatch.Chunks()
 			for _, chunk := range chunks {
+				if chunk.Type() == diff.Equal {
+					endHunk()
+					continue
+				}
 				lines := strings.Split(chunk.Content(), "\n")
 				for _, line := range lines {
 					if line == "" {
@@ -239,17 +1158,44 @@ func (r *gitRepository) getDiffStats(fromHash, toHash string) (*DiffStats, error
 					switch chunk.Type() {
 					case diff.Add:
 						stats.TotalAdditions++
+						file.Additions++
 						if !isExcluded {
 							stats.Additions++
+							added = append(added, line)
 						}
 					case diff.Delete:
 						stats.TotalDeletions++
+						file.Deletions++
 						if !isExcluded {
 							stats.Deletions++
+							deleted = append(deleted, line)
 						}
 					}
 				}
 			}
+
+			endHunk()
+
+			stats.FormattingAdditions += formattingAdded
+			stats.FormattingDeletions += formattingDeleted
+			if r.ignoreFormatting {
+				stats.Additions -= formattingAdded
+				stats.Deletions -= formattingDeleted
+				file.Additions -= formattingAdded
+				file.Deletions -= formattingDeleted
+			}
+
+			stats.Files = append(stats.Files, file)
+
+			if !isExcluded && (file.Additions > 0 || file.Deletions > 0) {
+				if stats.Languages == nil {
+					stats.Languages = make(map[string]LanguageStats)
+				}
+				lang := stats.Languages[file.Language]
+				lang.Additions += file.Additions
+				lang.Deletions += file.Deletions
+				stats.Languages[file.Language] = lang
+			}
 		}
 	}
 
@@ -259,6 +1205,175 @@ func (r *gitRepository) getDiffStats(fromHash, toHash string) (*DiffStats, error
 	return stats, nil
 }
 
+// binarySizeDelta returns how many bytes a binary file grew (or shrank) by.
+func (r *gitRepository) binarySizeDelta(from, to diff.File) (int64, error) {
+	var delta int64
+	if to != nil {
+		size, err := r.blobSize(to)
+		if err != nil {
+			return 0, err
+		}
+		delta += size
+	}
+	if from != nil {
+		size, err := r.blobSize(from)
+		if err != nil {
+			return 0, err
+		}
+		delta -= size
+	}
+
+	return delta, nil
+}
+
+func (r *gitRepository) blobSize(f diff.File) (int64, error) {
+	blob, err := r.repo.BlobObject(f.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get blob of %s: %w", f.Path(), err)
+	}
+
+	return blob.Size, nil
+}
+
+type lineChanges struct {
+	added   []string
+	deleted []string
+}
+
+// getMergeStats counts the lines a merge commit introduced on its own: lines
+// added relative to every parent (e.g. evil merges, conflict resolutions) and
+// lines that every parent had but the merge dropped.
+func (r *gitRepository) getMergeStats(parentHashes []string, mergeHash string) (*DiffStats, error) {
+	mergeTree, err := r.commitTree(mergeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge tree: %w", err)
+	}
+
+	var introduced map[string]*lineChanges
+	for i, parentHash := range parentHashes {
+		parentTree, err := r.commitTree(parentHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent tree: %w", err)
+		}
+
+		changes, err := collectLineChanges(parentTree, mergeTree)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			introduced = changes
+			continue
+		}
+
+		for filePath, lc := range introduced {
+			other, ok := changes[filePath]
+			if !ok {
+				delete(introduced, filePath)
+				continue
+			}
+			lc.added = intersectLines(lc.added, other.added)
+			lc.deleted = intersectLines(lc.deleted, other.deleted)
+		}
+	}
+
+	paths := make([]string, 0, len(introduced))
+	for filePath := range introduced {
+		paths = append(paths, filePath)
+	}
+
+	attrs, err := loadAttributes(mergeTree, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attributes: %w", err)
+	}
+
+	stats := &DiffStats{}
+	for filePath, lc := range introduced {
+		if len(lc.added) == 0 && len(lc.deleted) == 0 {
+			continue
+		}
+
+		stats.FilesChangedTotal++
+		stats.TotalAdditions += int64(len(lc.added))
+		stats.TotalDeletions += int64(len(lc.deleted))
+
+		if !r.shouldExcludeFile(filePath) && !attrs.Excluded(filePath) {
+			stats.FilesChanged++
+			stats.Additions += int64(len(lc.added))
+			stats.Deletions += int64(len(lc.deleted))
+		}
+	}
+
+	return stats, nil
+}
+
+func collectLineChanges(fromTree, toTree *object.Tree) (map[string]*lineChanges, error) {
+	changes, err := fromTree.Diff(toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	result := make(map[string]*lineChanges)
+	for _, change := range changes {
+		patch, err := change.Patch()
+		if err != nil {
+			continue
+		}
+
+		for _, filePatch := range patch.FilePatches() {
+			from, to := filePatch.Files()
+
+			var filePath string
+			if to != nil {
+				filePath = to.Path()
+			} else if from != nil {
+				filePath = from.Path()
+			}
+
+			lc, ok := result[filePath]
+			if !ok {
+				lc = &lineChanges{}
+				result[filePath] = lc
+			}
+
+			for _, chunk := range filePatch.Chunks() {
+				for _, line := range strings.Split(chunk.Content(), "\n") {
+					if line == "" {
+						continue
+					}
+					switch chunk.Type() {
+					case diff.Add:
+						lc.added = append(lc.added, line)
+					case diff.Delete:
+						lc.deleted = append(lc.deleted, line)
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// intersectLines returns the multiset intersection of a and b.
+func intersectLines(a, b []string) []string {
+	counts := make(map[string]int, len(b))
+	for _, line := range b {
+		counts[line]++
+	}
+
+	result := make([]string, 0)
+	for _, line := range a {
+		if counts[line] > 0 {
+			counts[line]--
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
 func (r *gitRepository) Close() error {
+	r.repo = nil
 	return nil
 }
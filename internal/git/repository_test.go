@@ -1,11 +1,18 @@
 package git
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // createTestRepo creates a test git repository with commits
@@ -162,6 +169,337 @@ func TestOpenRepository(t *testing.T) {
 	})
 }
 
+func TestOpenRepository_Layouts(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	srcDir := initTestRepo(t)
+	commitTestFile(t, srcDir, "pkg/a.go", numberedLines("a", 10), "A", base)
+	commitTestFile(t, srcDir, IgnoreFileName, "*.log\n", "Ignore logs", base.Add(time.Minute))
+	commitTestFile(t, srcDir, "pkg/debug.log", numberedLines("log", 10), "Add log", base.Add(2*time.Minute))
+
+	bareDir := filepath.Join(t.TempDir(), "repo.git")
+	runGit(t, srcDir, nil, "clone", "-q", "--bare", srcDir, bareDir)
+
+	worktreeDir := filepath.Join(t.TempDir(), "feature")
+	runGit(t, srcDir, nil, "worktree", "add", "-q", "-b", "feature", worktreeDir)
+	commitTestFile(t, worktreeDir, "b.go", numberedLines("b", 10), "B", base.Add(3*time.Minute))
+
+	separateDir := t.TempDir()
+	separateGitDir := filepath.Join(t.TempDir(), "separate.git")
+	runGit(t, srcDir, nil, "clone", "-q", "--separate-git-dir", separateGitDir, srcDir, separateDir)
+
+	tests := []struct {
+		name        string
+		repoPath    string
+		gitDir      string
+		wantCommits []string
+	}{
+		{"subdirectory of a working tree", filepath.Join(srcDir, "pkg"), "", []string{"Add log\n", "Ignore logs\n", "A\n"}},
+		{"bare repository", bareDir, "", []string{"Add log\n", "Ignore logs\n", "A\n"}},
+		{"linked worktree", worktreeDir, "", []string{"B\n", "Add log\n", "Ignore logs\n", "A\n"}},
+		{"git file pointing elsewhere", separateDir, "", []string{"Add log\n", "Ignore logs\n", "A\n"}},
+		{"explicit git directory with working tree", separateDir, separateGitDir, []string{"Add log\n", "Ignore logs\n", "A\n"}},
+		{"explicit git directory without working tree", "", separateGitDir, []string{"Add log\n", "Ignore logs\n", "A\n"}},
+		{"explicit linked worktree git directory", worktreeDir, filepath.Join(srcDir, ".git", "worktrees", "feature"), []string{"B\n", "Add log\n", "Ignore logs\n", "A\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := OpenRepository(tt.repoPath, &RepositoryOptions{GitDir: tt.gitDir})
+			if err != nil {
+				t.Fatalf("OpenRepository() unexpected error = %v", err)
+			}
+			defer repo.Close()
+
+			commits, err := repo.GetCommits(nil)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			var messages []string
+			for _, c := range commits {
+				messages = append(messages, c.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.wantCommits) {
+				t.Errorf("GetCommits() = %q, want %q", messages, tt.wantCommits)
+			}
+
+			pairs, err := repo.(*gitRepository).GetCommitPairs(commits)
+			if err != nil {
+				t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+			}
+			for _, pair := range pairs {
+				if pair.Current.Message == "Add log\n" && pair.Stats.Additions != 0 {
+					t.Errorf("Additions = %d, want the log excluded by %s", pair.Stats.Additions, IgnoreFileName)
+				}
+			}
+		})
+	}
+
+	t.Run("location identifies the git directory", func(t *testing.T) {
+		tests := []struct {
+			repoPath string
+			gitDir   string
+			want     string
+		}{
+			{srcDir, "", filepath.Join(srcDir, ".git")},
+			{filepath.Join(srcDir, "pkg") + "/", "", filepath.Join(srcDir, ".git")},
+			{srcDir, filepath.Join(srcDir, "pkg", "..", ".git"), filepath.Join(srcDir, ".git")},
+			{bareDir, "", bareDir},
+			{separateDir, "", separateGitDir},
+			{"", separateGitDir, separateGitDir},
+		}
+
+		for _, tt := range tests {
+			repo, err := OpenRepository(tt.repoPath, &RepositoryOptions{GitDir: tt.gitDir})
+			if err != nil {
+				t.Fatalf("OpenRepository(%q) unexpected error = %v", tt.repoPath, err)
+			}
+			if got := repo.(*gitRepository).Location(); got != tt.want {
+				t.Errorf("Location() of %q with git dir %q = %q, want %q", tt.repoPath, tt.gitDir, got, tt.want)
+			}
+			repo.Close()
+		}
+	})
+
+	t.Run("pending changes from a subdirectory", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(srcDir, "pkg", "a.go"), []byte(numberedLines("a", 30)), 0o600); err != nil {
+			t.Fatalf("Failed to write a.go: %v", err)
+		}
+		t.Cleanup(func() { runGit(t, srcDir, nil, "checkout", "-q", "--", "pkg/a.go") })
+
+		repo, err := OpenRepository(filepath.Join(srcDir, "pkg"), nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer repo.Close()
+
+		pair, err := repo.(*gitRepository).GetPendingChanges(nil)
+		if err != nil {
+			t.Fatalf("GetPendingChanges() unexpected error = %v", err)
+		}
+		if pair == nil || pair.Stats.Additions != 20 {
+			t.Errorf("GetPendingChanges() = %+v, want 20 additions", pair)
+		}
+	})
+
+	t.Run("bare repository has no pending changes", func(t *testing.T) {
+		repo, err := OpenRepository(bareDir, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer repo.Close()
+
+		if _, err := repo.(*gitRepository).GetPendingChanges(nil); err == nil {
+			t.Error("GetPendingChanges() expected error, got nil")
+		}
+		submodules, err := repo.(*gitRepository).Submodules()
+		if err != nil || len(submodules) != 0 {
+			t.Errorf("Submodules() = (%v, %v), want none", submodules, err)
+		}
+	})
+
+	t.Run("explicit git directory that is not one", func(t *testing.T) {
+		if _, err := OpenRepository(srcDir, &RepositoryOptions{GitDir: srcDir}); err == nil {
+			t.Error("OpenRepository() expected error, got nil")
+		}
+	})
+
+	t.Run("explicit git directory with a remote URL", func(t *testing.T) {
+		if _, err := OpenRepository("https://example.com/repo.git", &RepositoryOptions{GitDir: bareDir}); err == nil {
+			t.Error("OpenRepository() expected error, got nil")
+		}
+	})
+}
+
+func TestOpenRepository_Remote(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	srcDir := initTestRepo(t)
+	commitTestFile(t, srcDir, "a.txt", "a\n", "A", base)
+	commitTestFile(t, srcDir, "b.txt", "b\n", "B", base.Add(time.Minute))
+	runGit(t, srcDir, nil, "checkout", "-q", "-b", "feature")
+	commitTestFile(t, srcDir, "c.txt", "c\n", "C", base.Add(2*time.Minute))
+	runGit(t, srcDir, nil, "checkout", "-q", "main")
+
+	bareDir := filepath.Join(t.TempDir(), "repo.git")
+	runGit(t, srcDir, nil, "clone", "-q", "--bare", srcDir, bareDir)
+	url := "file://" + filepath.ToSlash(bareDir)
+
+	t.Run("clone full history", func(t *testing.T) {
+		repo, err := OpenRepository(url, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository(%q) unexpected error = %v", url, err)
+		}
+		defer repo.Close()
+
+		commits, err := repo.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 2 {
+			t.Errorf("len(commits) = %d, want 2", len(commits))
+		}
+
+		commits, err = repo.GetCommits(&CommitOptions{Branch: "feature"})
+		if err != nil {
+			t.Fatalf("GetCommits(feature) unexpected error = %v", err)
+		}
+		if len(commits) != 3 {
+			t.Errorf("len(commits) on feature = %d, want 3", len(commits))
+		}
+	})
+
+	t.Run("clone single branch with depth", func(t *testing.T) {
+		repo, err := OpenRepository(url, &RepositoryOptions{CloneDepth: 1, CloneBranch: "feature"})
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer repo.Close()
+
+		commits, err := repo.GetCommits(&CommitOptions{Branch: "feature"})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 1 {
+			t.Fatalf("len(commits) = %d, want 1", len(commits))
+		}
+		if commits[0].Message != "C\n" {
+			t.Errorf("Message = %q, want C", commits[0].Message)
+		}
+	})
+
+	t.Run("unreachable URL returns error", func(t *testing.T) {
+		repo, err := OpenRepository("file://"+filepath.ToSlash(filepath.Join(t.TempDir(), "missing.git")), nil)
+		if err == nil {
+			t.Fatal("OpenRepository() expected error for missing remote")
+		}
+		if repo != nil {
+			t.Errorf("OpenRepository() expected nil on error, got %v", repo)
+		}
+	})
+}
+
+func TestGitRepository_ShallowClone(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	srcDir := initTestRepo(t)
+	for i := 0; i < 4; i++ {
+		commitTestFile(t, srcDir, fmt.Sprintf("f%d.txt", i), "x\n", fmt.Sprintf("Commit %d", i), base.Add(time.Duration(i)*time.Hour))
+	}
+
+	shallowDir := filepath.Join(t.TempDir(), "shallow")
+	runGit(t, srcDir, nil, "clone", "-q", "--depth=2", "file://"+filepath.ToSlash(srcDir), shallowDir)
+
+	gitRepo, err := OpenRepository(shallowDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("len(commits) = %d, want 2", len(commits))
+	}
+	if commits[0].Shallow || !commits[1].Shallow {
+		t.Errorf("Shallow = %v, %v, want only the oldest commit marked", commits[0].Shallow, commits[1].Shallow)
+	}
+
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].Current.Hash != commits[0].Hash {
+		t.Errorf("len(pairs) = %d, want only the pair above the boundary", len(pairs))
+	}
+}
+
+func TestGitRepository_GetCommitPairs_Files(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "keep.go", "a\n", "Initial commit", base)
+	commitTestFile(t, tmpDir, "gone.go", "x\ny\n", "Add gone", base.Add(time.Hour))
+	commitTestFile(t, tmpDir, "moved.go", numberedLines("moved", 20), "Add moved", base.Add(2*time.Hour))
+
+	runGit(t, tmpDir, nil, "rm", "-q", "gone.go")
+	runGit(t, tmpDir, nil, "mv", "moved.go", "renamed.go")
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.md"), []byte("one\ntwo\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write notes.md: %v", err)
+	}
+	runGit(t, tmpDir, nil, "add", "notes.md")
+	commitTestFile(t, tmpDir, "keep.go", "a\nb\nc\n", "Mixed change", base.Add(3*time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{ExcludeFiles: []string{"*.md"}})
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+	if len(pairs) == 0 || pairs[0].Current.Message != "Mixed change\n" {
+		t.Fatalf("expected the newest pair to be the mixed change")
+	}
+
+	got := make(map[string]FileStats)
+	for _, f := range pairs[0].Stats.Files {
+		got[f.Path] = f
+	}
+
+	want := map[string]FileStats{
+		"keep.go":    {Path: "keep.go", Status: FileModified, Language: "Go", Additions: 2},
+		"gone.go":    {Path: "gone.go", Status: FileDeleted, Language: "Go", Deletions: 2},
+		"renamed.go": {Path: "renamed.go", OldPath: "moved.go", Status: FileModified, Language: "Go"},
+		"notes.md":   {Path: "notes.md", Status: FileAdded, Language: "Markdown", Additions: 2, Excluded: true},
+	}
+	if len(got) != len(want) {
+		t.Errorf("len(Files) = %d, want %d: %+v", len(got), len(want), pairs[0].Stats.Files)
+	}
+	for filePath, w := range want {
+		if got[filePath] != w {
+			t.Errorf("Files[%s] = %+v, want %+v", filePath, got[filePath], w)
+		}
+	}
+
+	wantLanguages := map[string]LanguageStats{"Go": {Additions: 2, Deletions: 2}}
+	if !reflect.DeepEqual(pairs[0].Stats.Languages, wantLanguages) {
+		t.Errorf("Languages = %+v, want %+v", pairs[0].Stats.Languages, wantLanguages)
+	}
+}
+
+func TestIsRemoteURL(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"https://github.com/owner/repo.git", true},
+		{"ssh://git@example.com/repo.git", true},
+		{"file:///srv/git/repo.git", true},
+		{"git@github.com:owner/repo.git", true},
+		{"/home/user/repo", false},
+		{".", false},
+		{"C:\\repos\\project", false},
+		{"./dir@v1:2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := isRemoteURL(tt.path); got != tt.want {
+				t.Errorf("isRemoteURL(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGitRepository_GetCommits(t *testing.T) {
 	repoPath := createTestRepo(t)
 	repo, err := OpenRepository(repoPath, nil)
@@ -240,6 +578,269 @@ func TestGitRepository_GetCommits(t *testing.T) {
 	})
 }
 
+func TestGitRepository_GetCommits_Window(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	for i := 0; i < 5; i++ {
+		commitTestFile(t, tmpDir, fmt.Sprintf("f%d.txt", i), "x\n", fmt.Sprintf("C%d", i), base.AddDate(0, 0, i))
+		if i == 1 {
+			runGit(t, tmpDir, nil, "tag", "v1.0")
+		}
+	}
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer repo.Close()
+
+	messages := func(commits []*Commit) []string {
+		result := make([]string, len(commits))
+		for i, c := range commits {
+			result[i] = strings.TrimSpace(c.Message)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		opts *CommitOptions
+		want []string
+	}{
+		{
+			name: "range from tag",
+			opts: &CommitOptions{Range: "v1.0..HEAD"},
+			want: []string{"C4", "C3", "C2"},
+		},
+		{
+			name: "open-ended range",
+			opts: &CommitOptions{Range: "v1.0.."},
+			want: []string{"C4", "C3", "C2"},
+		},
+		{
+			name: "range up to revision",
+			opts: &CommitOptions{Range: "..HEAD~3"},
+			want: []string{"C1", "C0"},
+		},
+		{
+			name: "since",
+			opts: &CommitOptions{Since: base.AddDate(0, 0, 3)},
+			want: []string{"C4", "C3"},
+		},
+		{
+			name: "until",
+			opts: &CommitOptions{Until: base.AddDate(0, 0, 1)},
+			want: []string{"C1", "C0"},
+		},
+		{
+			name: "range with window and depth",
+			opts: &CommitOptions{Range: "v1.0..HEAD", Until: base.AddDate(0, 0, 3), MaxDepth: 1},
+			want: []string{"C3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, err := repo.GetCommits(tt.opts)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			got := messages(commits)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("GetCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown revision", func(t *testing.T) {
+		if _, err := repo.GetCommits(&CommitOptions{Range: "nope..HEAD"}); err == nil {
+			t.Error("GetCommits() expected error for unknown revision")
+		}
+	})
+}
+
+func TestGitRepository_GetCommits_AllRefs(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "A", base)
+	runGit(t, tmpDir, datedEnv(base), "tag", "-a", "v1", "-m", "release")
+	commitTestFile(t, tmpDir, "b.txt", "b\n", "B", base.Add(time.Hour))
+	runGit(t, tmpDir, nil, "checkout", "-q", "-b", "feature/x")
+	commitTestFile(t, tmpDir, "c.txt", "c\n", "C", base.Add(2*time.Hour))
+	runGit(t, tmpDir, nil, "checkout", "-q", "-b", "feature/y", "v1")
+	commitTestFile(t, tmpDir, "d.txt", "d\n", "D", base.Add(3*time.Hour))
+	runGit(t, tmpDir, nil, "checkout", "-q", "main")
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer repo.Close()
+
+	byMessage := func(commits []*Commit) map[string]*Commit {
+		result := make(map[string]*Commit, len(commits))
+		for _, c := range commits {
+			result[strings.TrimSpace(c.Message)] = c
+		}
+		return result
+	}
+
+	t.Run("all refs de-duplicates shared commits", func(t *testing.T) {
+		commits, err := repo.GetCommits(&CommitOptions{AllRefs: true})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 4 {
+			t.Fatalf("len(commits) = %d, want 4", len(commits))
+		}
+		for i := 0; i < len(commits)-1; i++ {
+			if commits[i].Timestamp.Before(commits[i+1].Timestamp) {
+				t.Error("Commits should be in reverse chronological order")
+			}
+		}
+
+		got := byMessage(commits)
+		wantRefs := map[string]string{
+			"A": "refs/heads/feature/x,refs/heads/feature/y,refs/heads/main,refs/tags/v1",
+			"B": "refs/heads/feature/x,refs/heads/main",
+			"C": "refs/heads/feature/x",
+			"D": "refs/heads/feature/y",
+		}
+		for msg, want := range wantRefs {
+			if refs := strings.Join(got[msg].Refs, ","); refs != want {
+				t.Errorf("commit %s Refs = %s, want %s", msg, refs, want)
+			}
+		}
+	})
+
+	t.Run("ref patterns with range", func(t *testing.T) {
+		commits, err := repo.GetCommits(&CommitOptions{RefPatterns: []string{"refs/heads/feature/*"}, Range: "main.."})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		got := byMessage(commits)
+		if len(got) != 2 || got["C"] == nil || got["D"] == nil {
+			t.Errorf("GetCommits() = %v, want C and D", got)
+		}
+	})
+
+	t.Run("short ref patterns and max depth", func(t *testing.T) {
+		commits, err := repo.GetCommits(&CommitOptions{RefPatterns: []string{"feature/*"}, MaxDepth: 1})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 1 || strings.TrimSpace(commits[0].Message) != "D" {
+			t.Errorf("GetCommits() = %v, want only the newest commit D", commits)
+		}
+	})
+
+	t.Run("invalid combinations", func(t *testing.T) {
+		if _, err := repo.GetCommits(&CommitOptions{AllRefs: true, Branch: "main"}); err == nil {
+			t.Error("expected error combining branch with all refs")
+		}
+		if _, err := repo.GetCommits(&CommitOptions{AllRefs: true, Range: "v1..main"}); err == nil {
+			t.Error("expected error combining range end with all refs")
+		}
+		if _, err := repo.GetCommits(&CommitOptions{RefPatterns: []string{"refs/heads/nope/*"}}); err == nil {
+			t.Error("expected error when no refs match")
+		}
+	})
+}
+
+func TestGitRepository_IncludePaths(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "README.md", "root\n", "Root", base)
+	commitTestFile(t, tmpDir, "services/billing/main.go", "b1\nb2\n", "Billing", base.Add(time.Hour))
+	commitTestFile(t, tmpDir, "services/billing-ui/app.ts", "u1\n", "Billing UI", base.Add(2*time.Hour))
+	if err := os.WriteFile(filepath.Join(tmpDir, "services/billing/main.go"), []byte("b1\nb2\nb3\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("root\nmore\nlines\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, tmpDir, nil, "add", ".")
+	runGit(t, tmpDir, datedEnv(base.Add(3*time.Hour)), "commit", "-q", "-m", "Mixed")
+
+	gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{IncludePaths: []string{"./services/billing/"}})
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+
+	got := make([]string, len(commits))
+	for i, c := range commits {
+		got[i] = strings.TrimSpace(c.Message)
+	}
+	if strings.Join(got, ",") != "Mixed,Billing" {
+		t.Fatalf("GetCommits() = %v, want [Mixed Billing]", got)
+	}
+
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair.Current.Message) != "Mixed" {
+			continue
+		}
+		if pair.Stats.Additions != 1 || pair.Stats.FilesChanged != 1 {
+			t.Errorf("filtered stats = +%d in %d files, want +1 in 1 file", pair.Stats.Additions, pair.Stats.FilesChanged)
+		}
+		if pair.Stats.TotalAdditions != 3 || pair.Stats.FilesChangedTotal != 2 {
+			t.Errorf("total stats = +%d in %d files, want +3 in 2 files", pair.Stats.TotalAdditions, pair.Stats.FilesChangedTotal)
+		}
+	}
+}
+
+func TestNormalizePaths(t *testing.T) {
+	got := normalizePaths([]string{"./services/billing/", "docs"})
+	if strings.Join(got, ",") != "services/billing,docs" {
+		t.Errorf("normalizePaths() = %v", got)
+	}
+	if got := normalizePaths([]string{"docs", "."}); got != nil {
+		t.Errorf("normalizePaths() with root = %v, want nil", got)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		rng     string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{rng: "v1..v2", from: "v1", to: "v2"},
+		{rng: "v1..", from: "v1"},
+		{rng: "..v2", to: "v2"},
+		{rng: "v1...v2", wantErr: true},
+		{rng: "v1", wantErr: true},
+		{rng: "..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			from, to, err := parseRange(tt.rng)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRange(%q) error = %v, wantErr %v", tt.rng, err, tt.wantErr)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("parseRange(%q) = %q, %q, want %q, %q", tt.rng, from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
 func TestGitRepository_GetCommitPairs(t *testing.T) {
 	repoPath := createTestRepo(t)
 	gitRepo, err := OpenRepository(repoPath, nil)
@@ -305,6 +906,444 @@ func TestGitRepository_GetCommitPairs(t *testing.T) {
 	})
 }
 
+func TestGitRepository_GetCommitPairs_RealParents(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "A", base)
+	runGit(t, tmpDir, nil, "checkout", "-q", "-b", "feature")
+	commitTestFile(t, tmpDir, "b.txt", "b1\nb2\n", "B", base.Add(10*time.Minute))
+	runGit(t, tmpDir, nil, "checkout", "-q", "-")
+	commitTestFile(t, tmpDir, "c.txt", "c1\nc2\nc3\n", "C", base.Add(20*time.Minute))
+	runGit(t, tmpDir, datedEnv(base.Add(30*time.Minute)), "merge", "-q", "--no-ff", "-m", "Merge feature", "feature")
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+
+	if len(pairs) != 2 {
+		t.Fatalf("len(pairs) = %d, want 2", len(pairs))
+	}
+
+	for _, pair := range pairs {
+		if pair.Previous.Hash != pair.Current.Parents[0] {
+			t.Errorf("pair %q: Previous = %s, want first parent %s", pair.Current.Message, pair.Previous.Hash, pair.Current.Parents[0])
+		}
+		want := map[string]struct {
+			additions int64
+			delta     time.Duration
+		}{
+			"B\n": {2, 10 * time.Minute},
+			"C\n": {3, 20 * time.Minute},
+		}[pair.Current.Message]
+		if pair.Stats.Additions != want.additions {
+			t.Errorf("pair %q: Additions = %d, want %d", pair.Current.Message, pair.Stats.Additions, want.additions)
+		}
+		if pair.TimeDelta != want.delta {
+			t.Errorf("pair %q: TimeDelta = %v, want %v", pair.Current.Message, pair.TimeDelta, want.delta)
+		}
+	}
+}
+
+func TestGitRepository_GetCommitPairs_ParentOutsideSet(t *testing.T) {
+	repoPath := createTestRepo(t)
+	gitRepo, err := OpenRepository(repoPath, nil)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(&CommitOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+	if len(pairs) != 1 {
+		t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+	}
+	if pairs[0].Previous.Hash != commits[0].Parents[0] {
+		t.Errorf("Previous = %s, want %s", pairs[0].Previous.Hash, commits[0].Parents[0])
+	}
+	if pairs[0].Stats.Deletions != 2 {
+		t.Errorf("Deletions = %d, want 2", pairs[0].Stats.Deletions)
+	}
+}
+
+func TestGitRepository_GetCommitPairs_Merges(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "A", base)
+	runGit(t, tmpDir, nil, "checkout", "-q", "-b", "feature")
+	commitTestFile(t, tmpDir, "b.txt", "b1\nb2\nb3\n", "B", base.Add(10*time.Minute))
+	runGit(t, tmpDir, nil, "checkout", "-q", "-")
+	commitTestFile(t, tmpDir, "c.txt", "c1\nc2\n", "C", base.Add(20*time.Minute))
+	runGit(t, tmpDir, nil, "merge", "-q", "--no-ff", "--no-commit", "feature")
+	commitTestFile(t, tmpDir, "evil.txt", "e1\ne2\ne3\ne4\n", "Merge feature", base.Add(30*time.Minute))
+
+	t.Run("merges skipped by default", func(t *testing.T) {
+		gitRepo, err := OpenRepository(tmpDir, nil)
+		if err != nil {
+			t.Fatalf("Failed to open repository: %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		commits, _ := repo.GetCommits(nil)
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+		for _, pair := range pairs {
+			if pair.IsMerge {
+				t.Errorf("unexpected merge pair %s", pair.Current.Hash)
+			}
+		}
+	})
+
+	t.Run("merges diffed against first parent", func(t *testing.T) {
+		gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{IncludeMerges: true})
+		if err != nil {
+			t.Fatalf("Failed to open repository: %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		commits, _ := repo.GetCommits(nil)
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+
+		var merge *CommitPair
+		for _, pair := range pairs {
+			if pair.IsMerge {
+				merge = pair
+			}
+		}
+		if merge == nil {
+			t.Fatal("expected a merge pair")
+		}
+
+		if merge.Previous.Hash != merge.Current.Parents[0] {
+			t.Errorf("Previous = %s, want first parent %s", merge.Previous.Hash, merge.Current.Parents[0])
+		}
+		if merge.TimeDelta != 10*time.Minute {
+			t.Errorf("TimeDelta = %v, want 10m", merge.TimeDelta)
+		}
+		if merge.Stats.Additions != 7 {
+			t.Errorf("Stats.Additions = %d, want 7 (feature branch + merge content)", merge.Stats.Additions)
+		}
+		if merge.MergeStats == nil {
+			t.Fatal("MergeStats should not be nil")
+		}
+		if merge.MergeStats.Additions != 4 {
+			t.Errorf("MergeStats.Additions = %d, want 4", merge.MergeStats.Additions)
+		}
+		if merge.MergeStats.FilesChanged != 1 {
+			t.Errorf("MergeStats.FilesChanged = %d, want 1", merge.MergeStats.FilesChanged)
+		}
+	})
+}
+
+func TestGitRepository_GetCommits_TimeSource(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "A", base)
+	if err := os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("b\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write b.txt: %v", err)
+	}
+	runGit(t, tmpDir, nil, "add", "b.txt")
+	runGit(t, tmpDir, []string{
+		"GIT_AUTHOR_DATE=" + base.Add(-time.Hour).Format(time.RFC3339),
+		"GIT_COMMITTER_DATE=" + base.Add(2*time.Minute).Format(time.RFC3339),
+	}, "commit", "-q", "-m", "B")
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	t.Run("author time by default", func(t *testing.T) {
+		commits, err := repo.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if !commits[0].Timestamp.Equal(base.Add(-time.Hour)) {
+			t.Errorf("Timestamp = %v, want author time", commits[0].Timestamp)
+		}
+		if !commits[0].CommitterTimestamp.Equal(base.Add(2 * time.Minute)) {
+			t.Errorf("CommitterTimestamp = %v, want %v", commits[0].CommitterTimestamp, base.Add(2*time.Minute))
+		}
+		if commits[0].Committer != "Test User" || commits[0].CommitterEmail != "test@example.com" {
+			t.Errorf("Committer = %s <%s>", commits[0].Committer, commits[0].CommitterEmail)
+		}
+
+		pairs, _ := repo.GetCommitPairs(commits)
+		if len(pairs) != 0 {
+			t.Errorf("len(pairs) = %d, want 0 (author time goes backwards)", len(pairs))
+		}
+	})
+
+	t.Run("committer time drives time delta", func(t *testing.T) {
+		commits, err := repo.GetCommits(&CommitOptions{TimeSource: TimeSourceCommitter})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+
+		pairs, _ := repo.GetCommitPairs(commits[:1])
+		if len(pairs) != 1 {
+			t.Fatalf("len(pairs) = %d, want 1", len(pairs))
+		}
+		if pairs[0].TimeDelta != 2*time.Minute {
+			t.Errorf("TimeDelta = %v, want 2m", pairs[0].TimeDelta)
+		}
+	})
+
+	t.Run("unknown time source", func(t *testing.T) {
+		_, err := repo.GetCommits(&CommitOptions{TimeSource: "wall"})
+		if err == nil {
+			t.Fatal("GetCommits() expected error for unknown time source")
+		}
+	})
+}
+
+func TestGitRepository_GetCommitPairs_BinaryFiles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	image := func(size int) string {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i % 7)
+		}
+		return string(data)
+	}
+
+	commitTestFile(t, tmpDir, "logo.png", image(100), "Initial commit", base)
+	commitTestFile(t, tmpDir, "main.go", "package main\n", "Add main", base.Add(time.Hour))
+	commitTestFile(t, tmpDir, "logo.png", image(300), "Grow logo", base.Add(2*time.Hour))
+	runGit(t, tmpDir, nil, "rm", "-q", "logo.png")
+	commitTestFile(t, tmpDir, "main.go", "package main\nfunc main() {}\n", "Drop logo", base.Add(3*time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+
+	want := map[string]DiffStats{
+		"Add main\n":  {Additions: 1, FilesChanged: 1},
+		"Grow logo\n": {FilesChanged: 1, BinaryFiles: 1, BinarySizeDelta: 200},
+		"Drop logo\n": {Additions: 1, FilesChanged: 2, BinaryFiles: 1, BinarySizeDelta: -300},
+	}
+	for _, p := range pairs {
+		w := want[p.Current.Message]
+		got := p.Stats
+		if got.Additions != w.Additions || got.Deletions != w.Deletions || got.FilesChanged != w.FilesChanged ||
+			got.BinaryFiles != w.BinaryFiles || got.BinarySizeDelta != w.BinarySizeDelta {
+			t.Errorf("%q: got +%d -%d files=%d binary=%d (%+d bytes), want +%d -%d files=%d binary=%d (%+d bytes)",
+				p.Current.Message, got.Additions, got.Deletions, got.FilesChanged, got.BinaryFiles, got.BinarySizeDelta,
+				w.Additions, w.Deletions, w.FilesChanged, w.BinaryFiles, w.BinarySizeDelta)
+		}
+	}
+}
+
+func TestGitRepository_GetCommitPairs_Jobs(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	content := ""
+	for i := 0; i < 30; i++ {
+		content += fmt.Sprintf("line %d\n", i)
+		name := fmt.Sprintf("pkg%d/file.go", i%4)
+		commitTestFile(t, tmpDir, name, content, fmt.Sprintf("Commit %d", i), base.Add(time.Duration(i)*time.Minute))
+	}
+	// Packed objects are read through shared pack indexes, unlike loose ones.
+	runGit(t, tmpDir, nil, "gc", "-q")
+
+	pairsWithJobs := func(jobs int) []*CommitPair {
+		gitRepo, err := OpenRepository(tmpDir, &RepositoryOptions{Jobs: jobs})
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer gitRepo.Close()
+		repo := gitRepo.(*gitRepository)
+
+		commits, err := repo.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+		return pairs
+	}
+
+	serial := pairsWithJobs(1)
+	parallel := pairsWithJobs(8)
+
+	if len(serial) != 29 {
+		t.Fatalf("len(serial) = %d, want 29", len(serial))
+	}
+	if len(parallel) != len(serial) {
+		t.Fatalf("len(parallel) = %d, want %d", len(parallel), len(serial))
+	}
+	for i := range serial {
+		if parallel[i].Current.Hash != serial[i].Current.Hash {
+			t.Errorf("pair %d: Current = %s, want %s", i, parallel[i].Current.Hash[:7], serial[i].Current.Hash[:7])
+		}
+		if !reflect.DeepEqual(parallel[i].Stats, serial[i].Stats) {
+			t.Errorf("pair %d: Stats = %+v, want %+v", i, *parallel[i].Stats, *serial[i].Stats)
+		}
+	}
+
+	t.Run("negative jobs", func(t *testing.T) {
+		if _, err := OpenRepository(tmpDir, &RepositoryOptions{Jobs: -1}); err == nil {
+			t.Error("OpenRepository() should reject negative jobs")
+		}
+	})
+}
+
+func TestRunParallel(t *testing.T) {
+	for _, jobs := range []int{1, 3, 100} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			results := make([]int, 10)
+			runParallel(len(results), jobs, func(i int) {
+				results[i] = i * i
+			})
+			for i, got := range results {
+				if got != i*i {
+					t.Errorf("results[%d] = %d, want %d", i, got, i*i)
+				}
+			}
+		})
+	}
+
+	t.Run("no work", func(t *testing.T) {
+		runParallel(0, 4, func(int) {
+			t.Error("fn should not be called")
+		})
+	})
+}
+
+func TestGitRepository_GetCommits_ExcludeRevisions(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "First", base)
+	commitTestFile(t, tmpDir, "b.txt", "b\n", "Second", base.Add(time.Hour))
+
+	gitRepo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer gitRepo.Close()
+	repo := gitRepo.(*gitRepository)
+
+	tips, err := repo.ResolveTips(nil)
+	if err != nil {
+		t.Fatalf("ResolveTips() unexpected error = %v", err)
+	}
+	previousTip := tips["HEAD"]
+	if previousTip == "" {
+		t.Fatalf("ResolveTips() = %v, want a HEAD entry", tips)
+	}
+
+	commitTestFile(t, tmpDir, "c.txt", "c\n", "Third", base.Add(2*time.Hour))
+	commitTestFile(t, tmpDir, "d.txt", "d\n", "Fourth", base.Add(3*time.Hour))
+
+	t.Run("skips history of previous tips", func(t *testing.T) {
+		commits, err := repo.GetCommits(&CommitOptions{ExcludeRevisions: []string{previousTip}})
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 2 || commits[0].Message != "Fourth\n" || commits[1].Message != "Third\n" {
+			t.Fatalf("GetCommits() = %d commits, want Fourth and Third", len(commits))
+		}
+
+		pairs, err := repo.GetCommitPairs(commits)
+		if err != nil {
+			t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+		}
+		if len(pairs) != 2 || pairs[1].Previous.Hash != previousTip {
+			t.Errorf("oldest new commit should pair with the previous tip %s", previousTip[:7])
+		}
+	})
+
+	t.Run("unknown revisions fail", func(t *testing.T) {
+		_, err := repo.GetCommits(&CommitOptions{ExcludeRevisions: []string{"0123456789012345678901234567890123456789"}})
+		if !errors.Is(err, ErrUnknownExcludedRevision) {
+			t.Errorf("GetCommits() error = %v, want ErrUnknownExcludedRevision", err)
+		}
+	})
+
+	t.Run("tips by ref", func(t *testing.T) {
+		runGit(t, tmpDir, nil, "branch", "feature", previousTip)
+
+		tips, err := repo.ResolveTips(&CommitOptions{AllRefs: true})
+		if err != nil {
+			t.Fatalf("ResolveTips() unexpected error = %v", err)
+		}
+		if tips["refs/heads/feature"] != previousTip || tips["refs/heads/main"] == previousTip || len(tips) != 2 {
+			t.Errorf("ResolveTips() = %v, want main and feature tips", tips)
+		}
+
+		tips, err = repo.ResolveTips(&CommitOptions{Branch: "feature"})
+		if err != nil {
+			t.Fatalf("ResolveTips() unexpected error = %v", err)
+		}
+		if tips["feature"] != previousTip {
+			t.Errorf("ResolveTips() = %v, want feature at %s", tips, previousTip[:7])
+		}
+	})
+}
+
+func TestIntersectLines(t *testing.T) {
+	got := intersectLines([]string{"a", "b", "b", "c"}, []string{"b", "c", "c", "d"})
+	want := []string{"b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("intersectLines() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("intersectLines()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
 func TestGitRepository_ShouldExcludeFile(t *testing.T) {
 	repoPath := createTestRepo(t)
 
@@ -399,3 +1438,96 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// initTestRepo creates an empty git repository with a configured identity
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	runGit(t, tmpDir, nil, "init", "-q", "-b", "main")
+	runGit(t, tmpDir, nil, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, nil, "config", "user.name", "Test User")
+	runGit(t, tmpDir, nil, "config", "commit.gpgsign", "false")
+
+	return tmpDir
+}
+
+// runGit runs a git command in dir with optional extra environment
+func runGit(t *testing.T, dir string, env []string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return string(out)
+}
+
+// datedEnv pins both author and committer dates so tests need no sleeps
+func datedEnv(when time.Time) []string {
+	date := when.Format(time.RFC3339)
+	return []string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}
+}
+
+// commitTestFile writes a file and commits it at the given time
+func commitTestFile(t *testing.T, dir, name, content, message string, when time.Time) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+
+	runGit(t, dir, nil, "add", name)
+	runGit(t, dir, datedEnv(when), "commit", "-q", "-m", message)
+}
+
+func TestContainingRefs(t *testing.T) {
+	hash := func(i int) plumbing.Hash {
+		return plumbing.NewHash(fmt.Sprintf("%040x", i+1))
+	}
+
+	// A linear history 0 <- 1 <- ... <- 99 tagged at every commit, plus a
+	// side commit 100 off 10 merged back by 101 on top of 99.
+	var visited []*object.Commit
+	var tips []refTip
+	for i := 0; i < 100; i++ {
+		c := &object.Commit{Hash: hash(i)}
+		if i > 0 {
+			c.ParentHashes = []plumbing.Hash{hash(i - 1)}
+		}
+		visited = append(visited, c)
+		tips = append(tips, refTip{name: fmt.Sprintf("refs/tags/v%d", i), hash: hash(i)})
+	}
+	visited = append(visited,
+		&object.Commit{Hash: hash(100), ParentHashes: []plumbing.Hash{hash(10)}},
+		&object.Commit{Hash: hash(101), ParentHashes: []plumbing.Hash{hash(99), hash(100)}},
+	)
+	tips = append(tips,
+		refTip{name: "refs/heads/side", hash: hash(100)},
+		refTip{name: "refs/heads/main", hash: hash(101)},
+	)
+
+	refs := containingRefs(tips, visited)
+
+	if got := refs[hash(99)]; len(got) != 2 || got[0] != "refs/tags/v99" || got[1] != "refs/heads/main" {
+		t.Errorf("refs of 99 = %v, want v99 and main", got)
+	}
+	if got := refs[hash(100)]; len(got) != 2 || got[0] != "refs/heads/side" || got[1] != "refs/heads/main" {
+		t.Errorf("refs of 100 = %v, want side and main", got)
+	}
+	// Commit 10 is in tags v10..v99, side and main.
+	if got := refs[hash(10)]; len(got) != 92 || got[0] != "refs/tags/v10" || got[91] != "refs/heads/main" {
+		t.Errorf("refs of 10 = %d refs (%v...), want 92", len(got), got[:1])
+	}
+	if got := refs[hash(0)]; len(got) != 102 {
+		t.Errorf("refs of 0 = %d refs, want all 102", len(got))
+	}
+}
@@ -0,0 +1,353 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// SignatureFormat is the kind of signature a commit carries.
+type SignatureFormat string
+
+const (
+	SignatureOpenPGP SignatureFormat = "gpg"
+	SignatureSSH     SignatureFormat = "ssh"
+	SignatureX509    SignatureFormat = "x509"
+)
+
+// SignatureStatus is the outcome of verifying a signature against the
+// keyring given in RepositoryOptions.Keyring.
+type SignatureStatus string
+
+const (
+	// SignatureUnchecked means no keyring was given.
+	SignatureUnchecked SignatureStatus = "unchecked"
+	// SignatureGood means the signature verifies with a key of the keyring.
+	SignatureGood SignatureStatus = "good"
+	// SignatureUntrusted means the signature was made by a key missing from
+	// the keyring or does not verify.
+	SignatureUntrusted SignatureStatus = "untrusted"
+)
+
+// Signature describes the signature of a signed commit.
+type Signature struct {
+	Format SignatureFormat
+
+	// KeyID is the long ID of an OpenPGP key or the SHA256 fingerprint of
+	// an SSH key. It is taken from the signature and also set for untrusted
+	// signatures.
+	KeyID string
+
+	Status SignatureStatus
+
+	// Signer is the primary identity of the OpenPGP key or the principals of
+	// the SSH allowed signer that verified the signature.
+	Signer string
+}
+
+const (
+	pgpKeyBlockBegin = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	pgpKeyBlockEnd   = "-----END PGP PUBLIC KEY BLOCK-----"
+	sshSigBegin      = "-----BEGIN SSH SIGNATURE-----"
+	sshSigEnd        = "-----END SSH SIGNATURE-----"
+	sshSigMagic      = "SSHSIG"
+	sshSigNamespace  = "git"
+)
+
+// keyring holds the keys signatures are verified with: armored OpenPGP public
+// keys and SSH allowed signers ("principals [options] key-type key").
+type keyring struct {
+	pgp openpgp.EntityList
+	ssh []allowedSigner
+}
+
+type allowedSigner struct {
+	principals string
+	key        ssh.PublicKey
+}
+
+// loadKeyring reads a keyring file. An empty path disables verification.
+func loadKeyring(filePath string) (*keyring, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyring: %w", err)
+	}
+	defer f.Close()
+
+	return parseKeyring(f)
+}
+
+func parseKeyring(r io.Reader) (*keyring, error) {
+	kr := &keyring{}
+
+	var block *strings.Builder
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == pgpKeyBlockBegin:
+			block = &strings.Builder{}
+			block.WriteString(line + "\n")
+			continue
+		case block != nil:
+			block.WriteString(line + "\n")
+			if line == pgpKeyBlockEnd {
+				entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(block.String()))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read OpenPGP keys: %w", err)
+				}
+				kr.pgp = append(kr.pgp, entities...)
+				block = nil
+			}
+			continue
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		principals, key, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid allowed signer on line %d", lineNo)
+		}
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(key)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed signer on line %d: %w", lineNo, err)
+		}
+		kr.ssh = append(kr.ssh, allowedSigner{principals: principals, key: pub})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+	if block != nil {
+		return nil, fmt.Errorf("failed to read OpenPGP keys: unterminated key block")
+	}
+
+	return kr, nil
+}
+
+// commitSignature describes the signature of c, verifying it when a keyring
+// is given. It returns nil for unsigned commits. Unparsable signatures are
+// reported without a key ID rather than failing the analysis.
+func commitSignature(c *object.Commit, kr *keyring) *Signature {
+	if c.PGPSignature == "" {
+		return nil
+	}
+
+	sig := &Signature{Status: SignatureUnchecked}
+	if kr != nil {
+		sig.Status = SignatureUntrusted
+	}
+
+	switch {
+	case strings.Contains(c.PGPSignature, "BEGIN PGP SIGNATURE"):
+		sig.Format = SignatureOpenPGP
+		sig.KeyID = pgpKeyID(c.PGPSignature)
+		if kr != nil {
+			if signer, err := verifyPGP(c, kr.pgp); err == nil {
+				sig.Status = SignatureGood
+				sig.Signer = signer
+			}
+		}
+	case strings.Contains(c.PGPSignature, sshSigBegin):
+		sig.Format = SignatureSSH
+		parsed, err := parseSSHSig(c.PGPSignature)
+		if err != nil {
+			return sig
+		}
+		sig.KeyID = ssh.FingerprintSHA256(parsed.publicKey)
+		if kr != nil {
+			if signer, err := verifySSH(c, parsed, kr.ssh); err == nil {
+				sig.Status = SignatureGood
+				sig.Signer = signer
+			}
+		}
+	default:
+		sig.Format = SignatureX509
+	}
+
+	return sig
+}
+
+func pgpKeyID(armored string) string {
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return ""
+	}
+
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return ""
+	}
+
+	s, ok := p.(*packet.Signature)
+	if !ok || s.IssuerKeyId == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%016X", *s.IssuerKeyId)
+}
+
+// signedPayload returns the commit object as it was signed, i.e. without its
+// signature header.
+func signedPayload(c *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(encoded); err != nil {
+		return nil, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	r, err := encoded.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func verifyPGP(c *object.Commit, keys openpgp.EntityList) (string, error) {
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no OpenPGP keys")
+	}
+
+	payload, err := signedPayload(c)
+	if err != nil {
+		return "", err
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(keys, bytes.NewReader(payload), strings.NewReader(c.PGPSignature), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		return identity.Name, nil
+	}
+	return "", nil
+}
+
+// sshSig is an SSH signature as described in OpenSSH's PROTOCOL.sshsig.
+type sshSig struct {
+	publicKey     ssh.PublicKey
+	namespace     string
+	reserved      string
+	hashAlgorithm string
+	signature     *ssh.Signature
+}
+
+func parseSSHSig(armored string) (*sshSig, error) {
+	start := strings.Index(armored, sshSigBegin)
+	end := strings.Index(armored, sshSigEnd)
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("malformed SSH signature")
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(armored[start+len(sshSigBegin):end]), ""))
+	if err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return nil, fmt.Errorf("malformed SSH signature: bad magic")
+	}
+
+	var wire struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(blob[len(sshSigMagic):], &wire); err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+
+	pub, err := ssh.ParsePublicKey(wire.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("malformed SSH signature key: %w", err)
+	}
+
+	var sig struct {
+		Format string
+		Blob   []byte
+		Rest   []byte `ssh:"rest"`
+	}
+	if err := ssh.Unmarshal(wire.Signature, &sig); err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+
+	return &sshSig{
+		publicKey:     pub,
+		namespace:     wire.Namespace,
+		reserved:      wire.Reserved,
+		hashAlgorithm: wire.HashAlgorithm,
+		signature:     &ssh.Signature{Format: sig.Format, Blob: sig.Blob, Rest: sig.Rest},
+	}, nil
+}
+
+func verifySSH(c *object.Commit, sig *sshSig, signers []allowedSigner) (string, error) {
+	if sig.namespace != sshSigNamespace {
+		return "", fmt.Errorf("unexpected signature namespace %q", sig.namespace)
+	}
+
+	var principals string
+	found := false
+	for _, s := range signers {
+		if bytes.Equal(s.key.Marshal(), sig.publicKey.Marshal()) {
+			principals, found = s.principals, true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("signing key is not an allowed signer")
+	}
+
+	payload, err := signedPayload(c)
+	if err != nil {
+		return "", err
+	}
+
+	var digest []byte
+	switch sig.hashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(payload)
+		digest = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(payload)
+		digest = sum[:]
+	default:
+		return "", fmt.Errorf("unsupported signature hash %q", sig.hashAlgorithm)
+	}
+
+	signed := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.namespace, sig.reserved, sig.hashAlgorithm, digest})...)
+
+	if err := sig.publicKey.Verify(signed, sig.signature); err != nil {
+		return "", fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	return principals, nil
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantSSH int
+		wantErr bool
+	}{
+		{"empty", "", 0, false},
+		{"comments only", "# allowed signers\n\n", 0, false},
+		{"allowed signer", "dev@example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHq6S0G6t5cQ9BqZ0fBZ3Kq5dVQZ5d3iJ3ZQkGk9lY6M\n", 1, false},
+		{"missing key", "dev@example.com\n", 0, true},
+		{"invalid key", "dev@example.com ssh-ed25519 not-base64\n", 0, true},
+		{"unterminated key block", pgpKeyBlockBegin + "\n\nabc\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kr, err := parseKeyring(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(kr.ssh) != tt.wantSSH {
+				t.Errorf("parseKeyring() got %d allowed signers, want %d", len(kr.ssh), tt.wantSSH)
+			}
+		})
+	}
+}
+
+func TestGetCommits_Signatures(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tmpDir := initTestRepo(t)
+	keyDir := t.TempDir()
+
+	commitTestFile(t, tmpDir, "a.txt", "a\n", "Unsigned", base)
+
+	sshKey := filepath.Join(keyDir, "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", sshKey).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+	}
+	runGit(t, tmpDir, datedEnv(base.Add(time.Hour)), "-c", "gpg.format=ssh", "-c", "user.signingkey="+sshKey,
+		"commit", "--allow-empty", "-S", "-m", "SSH signed")
+
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create OpenPGP key: %v", err)
+	}
+	r, err := git.PlainOpen(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	sig := &object.Signature{Name: "Test User", Email: "test@example.com", When: base.Add(2 * time.Hour)}
+	if _, err := wt.Commit("PGP signed", &git.CommitOptions{
+		Author:            sig,
+		Committer:         sig,
+		SignKey:           entity,
+		AllowEmptyCommits: true,
+	}); err != nil {
+		t.Fatalf("Failed to create signed commit: %v", err)
+	}
+
+	var armored bytes.Buffer
+	w, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("Failed to armor key: %v", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("Failed to serialize key: %v", err)
+	}
+	w.Close()
+
+	pub, err := os.ReadFile(sshKey + ".pub")
+	if err != nil {
+		t.Fatalf("Failed to read public key: %v", err)
+	}
+	trusted := filepath.Join(keyDir, "trusted")
+	content := armored.String() + "\n# allowed signers\ntest@example.com " + string(pub)
+	if err := os.WriteFile(trusted, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+	empty := filepath.Join(keyDir, "empty")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatalf("Failed to write keyring: %v", err)
+	}
+
+	pgpKeyID := strings.ToUpper(entity.PrimaryKey.KeyIdString())
+
+	tests := []struct {
+		name    string
+		keyring string
+		want    map[string]*Signature
+	}{
+		{
+			name: "no keyring",
+			want: map[string]*Signature{
+				"Unsigned":   nil,
+				"SSH signed": {Format: SignatureSSH, Status: SignatureUnchecked},
+				"PGP signed": {Format: SignatureOpenPGP, KeyID: pgpKeyID, Status: SignatureUnchecked},
+			},
+		},
+		{
+			name:    "trusted keys",
+			keyring: trusted,
+			want: map[string]*Signature{
+				"Unsigned":   nil,
+				"SSH signed": {Format: SignatureSSH, Status: SignatureGood, Signer: "test@example.com"},
+				"PGP signed": {Format: SignatureOpenPGP, KeyID: pgpKeyID, Status: SignatureGood, Signer: "Test User <test@example.com>"},
+			},
+		},
+		{
+			name:    "unknown keys",
+			keyring: empty,
+			want: map[string]*Signature{
+				"Unsigned":   nil,
+				"SSH signed": {Format: SignatureSSH, Status: SignatureUntrusted},
+				"PGP signed": {Format: SignatureOpenPGP, KeyID: pgpKeyID, Status: SignatureUntrusted},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := OpenRepository(tmpDir, &RepositoryOptions{Keyring: tt.keyring})
+			if err != nil {
+				t.Fatalf("OpenRepository() unexpected error = %v", err)
+			}
+			defer repo.Close()
+
+			commits, err := repo.GetCommits(nil)
+			if err != nil {
+				t.Fatalf("GetCommits() unexpected error = %v", err)
+			}
+			if len(commits) != len(tt.want) {
+				t.Fatalf("GetCommits() returned %d commits, want %d", len(commits), len(tt.want))
+			}
+
+			for _, c := range commits {
+				want := tt.want[strings.TrimSpace(c.Message)]
+				got := c.Signature
+				if want == nil || got == nil {
+					if want != got {
+						t.Errorf("%q: Signature = %+v, want %+v", c.Message, got, want)
+					}
+					continue
+				}
+				if got.Format == SignatureSSH && !strings.HasPrefix(got.KeyID, "SHA256:") {
+					t.Errorf("%q: KeyID = %q, want an SSH fingerprint", c.Message, got.KeyID)
+				}
+				if got.Format != want.Format || got.Status != want.Status || got.Signer != want.Signer ||
+					(want.KeyID != "" && got.KeyID != want.KeyID) {
+					t.Errorf("%q: Signature = %+v, want %+v", c.Message, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("missing keyring", func(t *testing.T) {
+		if _, err := OpenRepository(tmpDir, &RepositoryOptions{Keyring: filepath.Join(keyDir, "missing")}); err == nil {
+			t.Error("OpenRepository() should fail for a missing keyring")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Submodule is a submodule declared in .gitmodules whose working tree is
+// checked out, i.e. one that git submodule update has initialized.
+type Submodule struct {
+	Name string
+	// Path is relative to the root of the repository that declares it.
+	Path string
+	URL  string
+	// Dir is the submodule's working tree on disk.
+	Dir string
+}
+
+// Submodules returns the initialized submodules of the working tree, sorted
+// by path. Submodules that were never initialized have no history to analyze
+// and are left out, as are all of them in a bare repository.
+func (r *gitRepository) Submodules() ([]Submodule, error) {
+	wt, err := r.repo.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	declared, err := wt.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	var submodules []Submodule
+	for _, sm := range declared {
+		c := sm.Config()
+		if _, err := wt.Filesystem.Lstat(path.Join(c.Path, ".git")); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat submodule %s: %w", c.Path, err)
+		}
+		submodules = append(submodules, Submodule{
+			Name: c.Name,
+			Path: c.Path,
+			URL:  c.URL,
+			Dir:  filepath.Join(wt.Filesystem.Root(), filepath.FromSlash(c.Path)),
+		})
+	}
+
+	sort.Slice(submodules, func(i, j int) bool { return submodules[i].Path < submodules[j].Path })
+
+	return submodules, nil
+}
+
+// isSubmoduleChange reports whether change updates the commit recorded for a
+// submodule, which has no blob to diff.
+func isSubmoduleChange(change *object.Change) bool {
+	return change.From.TreeEntry.Mode == filemode.Submodule || change.To.TreeEntry.Mode == filemode.Submodule
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// addTestSubmodule adds the repository at src as a submodule at subPath of dir
+func addTestSubmodule(t *testing.T, dir, src, subPath string, when time.Time) {
+	t.Helper()
+
+	runGit(t, dir, nil, "-c", "protocol.file.allow=always", "submodule", "add", "-q", src, subPath)
+	runGit(t, dir, datedEnv(when), "commit", "-q", "-m", "Add submodule "+subPath)
+}
+
+func TestGitRepository_Submodules(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	lib := initTestRepo(t)
+	commitTestFile(t, lib, "lib.go", numberedLines("lib", 10), "Add lib", base)
+
+	tmpDir := initTestRepo(t)
+	commitTestFile(t, tmpDir, "main.go", numberedLines("main", 10), "Add main", base)
+	addTestSubmodule(t, tmpDir, lib, "vendor/lib", base.Add(time.Minute))
+	addTestSubmodule(t, tmpDir, lib, "third_party/other", base.Add(2*time.Minute))
+	runGit(t, tmpDir, nil, "submodule", "deinit", "-q", "third_party/other")
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+
+	submodules, err := repo.(*gitRepository).Submodules()
+	if err != nil {
+		t.Fatalf("Submodules() unexpected error = %v", err)
+	}
+
+	want := []Submodule{{Name: "vendor/lib", Path: "vendor/lib", URL: lib, Dir: filepath.Join(tmpDir, "vendor", "lib")}}
+	if !reflect.DeepEqual(submodules, want) {
+		t.Fatalf("Submodules() = %+v, want %+v", submodules, want)
+	}
+
+	t.Run("submodule history", func(t *testing.T) {
+		sub, err := OpenRepository(submodules[0].Dir, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer sub.Close()
+
+		commits, err := sub.GetCommits(nil)
+		if err != nil {
+			t.Fatalf("GetCommits() unexpected error = %v", err)
+		}
+		if len(commits) != 1 || commits[0].Message != "Add lib\n" {
+			t.Errorf("GetCommits() = %+v, want the lib history", commits)
+		}
+	})
+
+	t.Run("no submodules", func(t *testing.T) {
+		plain, err := OpenRepository(lib, nil)
+		if err != nil {
+			t.Fatalf("OpenRepository() unexpected error = %v", err)
+		}
+		defer plain.Close()
+
+		submodules, err := plain.(*gitRepository).Submodules()
+		if err != nil {
+			t.Fatalf("Submodules() unexpected error = %v", err)
+		}
+		if len(submodules) != 0 {
+			t.Errorf("Submodules() = %+v, want none", submodules)
+		}
+	})
+}
+
+func TestGitRepository_GetCommitPairs_SubmoduleBump(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	lib := initTestRepo(t)
+	commitTestFile(t, lib, "lib.go", numberedLines("lib", 10), "Add lib", base)
+
+	tmpDir := initTestRepo(t)
+	commitTestFile(t, tmpDir, "main.go", numberedLines("main", 10), "Add main", base)
+	addTestSubmodule(t, tmpDir, lib, "vendor/lib", base.Add(time.Minute))
+
+	commitTestFile(t, lib, "lib.go", numberedLines("lib", 500), "Grow lib", base.Add(2*time.Minute))
+	subDir := filepath.Join(tmpDir, "vendor", "lib")
+	runGit(t, subDir, nil, "pull", "-q", "origin", "main")
+	runGit(t, tmpDir, nil, "add", "vendor/lib")
+	runGit(t, tmpDir, datedEnv(base.Add(3*time.Minute)), "commit", "-q", "-m", "Bump lib")
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+	pairs, err := repo.(*gitRepository).GetCommitPairs(commits)
+	if err != nil {
+		t.Fatalf("GetCommitPairs() unexpected error = %v", err)
+	}
+
+	if len(pairs) != 2 || pairs[0].Current.Message != "Bump lib\n" {
+		t.Fatalf("GetCommitPairs() returned %d pairs, want the bump and the submodule addition", len(pairs))
+	}
+
+	stats := pairs[0].Stats
+	if stats.SubmoduleUpdates != 1 || stats.Additions != 0 || stats.BinaryFiles != 0 || stats.FilesChanged != 1 {
+		t.Errorf("Stats = %+v, want one submodule update without lines", stats)
+	}
+	wantFiles := []FileStats{{Path: "vendor/lib", Status: FileModified, Submodule: true}}
+	if !reflect.DeepEqual(stats.Files, wantFiles) {
+		t.Errorf("Files = %+v, want %+v", stats.Files, wantFiles)
+	}
+	if added := pairs[1].Stats.Files; len(added) != 2 || added[1] != (FileStats{Path: "vendor/lib", Status: FileAdded, Submodule: true}) {
+		t.Errorf("Files = %+v, want .gitmodules and the added submodule", added)
+	}
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a "Key: value" line from the trailer block at the end of a
+// commit message, e.g. Co-authored-by or Signed-off-by.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+var trailerLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// parseTrailers returns the trailers of a commit message. Like git
+// interpret-trailers, only the last paragraph is considered, it must not be
+// the subject, and every line of it must be a trailer, a continuation line
+// starting with whitespace or a "(cherry picked from ...)" note.
+func parseTrailers(message string) []Trailer {
+	message = strings.TrimRight(strings.ReplaceAll(message, "\r\n", "\n"), " \t\n")
+
+	idx := strings.LastIndex(message, "\n\n")
+	if idx < 0 {
+		return nil
+	}
+	block := strings.Trim(message[idx+2:], "\n")
+
+	var trailers []Trailer
+	for _, line := range strings.Split(block, "\n") {
+		switch {
+		case strings.HasPrefix(line, "(cherry picked from commit "):
+			continue
+		case line != "" && (line[0] == ' ' || line[0] == '\t'):
+			if len(trailers) == 0 {
+				return nil
+			}
+			last := &trailers[len(trailers)-1]
+			last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+			continue
+		}
+
+		m := trailerLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+	}
+
+	return trailers
+}
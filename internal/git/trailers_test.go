@@ -0,0 +1,83 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{
+			name:    "co-author and sign-off",
+			message: "Add parser\n\nLonger description.\n\nCo-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: John Doe <john@example.com>\n",
+			want: []Trailer{
+				{Key: "Co-authored-by", Value: "Jane Doe <jane@example.com>"},
+				{Key: "Signed-off-by", Value: "John Doe <john@example.com>"},
+			},
+		},
+		{
+			name:    "continuation line",
+			message: "Fix bug\n\nReviewed-by: Jane\n  Doe\n",
+			want:    []Trailer{{Key: "Reviewed-by", Value: "Jane Doe"}},
+		},
+		{
+			name:    "cherry-pick note is skipped",
+			message: "Fix bug\n\nSigned-off-by: Jane <jane@example.com>\n(cherry picked from commit abc123)\n",
+			want:    []Trailer{{Key: "Signed-off-by", Value: "Jane <jane@example.com>"}},
+		},
+		{
+			name:    "CRLF line endings",
+			message: "Fix bug\r\n\r\nCo-authored-by: Jane <jane@example.com>\r\n",
+			want:    []Trailer{{Key: "Co-authored-by", Value: "Jane <jane@example.com>"}},
+		},
+		{
+			name:    "subject only",
+			message: "Fix: handle empty input\n",
+			want:    nil,
+		},
+		{
+			name:    "last paragraph is prose",
+			message: "Fix bug\n\nCo-authored-by: Jane <jane@example.com>\nand some more text\n",
+			want:    nil,
+		},
+		{
+			name:    "empty message",
+			message: "",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTrailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommits_Trailers(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	commitTestFile(t, tmpDir, "main.go", "package main\n", "Add main\n\nCo-authored-by: Bot <bot@example.com>", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+
+	repo, err := OpenRepository(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("OpenRepository() unexpected error = %v", err)
+	}
+	defer repo.Close()
+
+	commits, err := repo.GetCommits(nil)
+	if err != nil {
+		t.Fatalf("GetCommits() unexpected error = %v", err)
+	}
+
+	want := []Trailer{{Key: "Co-authored-by", Value: "Bot <bot@example.com>"}}
+	if len(commits) != 1 || !reflect.DeepEqual(commits[0].Trailers, want) {
+		t.Errorf("Trailers = %+v, want %+v", commits[0].Trailers, want)
+	}
+}
@@ -20,9 +20,28 @@ type RepositoryStats struct {
 	TotalLOCDeleted      int64
 	UnfilteredLOCAdded   int64
 	UnfilteredLOCDeleted int64
+	MergeCommitPairs     int
+	MergeLOCAdded        int64
+	MergeLOCDeleted      int64
 	AverageVelocity      float64
 	MedianVelocity       float64
 	VelocityPercentile   *Percentiles
+
+	// Velocities holds the per-pair addition velocities the averages and
+	// percentiles are computed from, so that stats can be merged.
+	Velocities []float64
+
+	// Languages is keyed by language name, see git.DiffStats.Languages.
+	Languages map[string]*LanguageStats
+}
+
+// LanguageStats totals the filtered lines changed in one language.
+// CommitCount counts the non-merge pairs that touched it.
+type LanguageStats struct {
+	Name        string
+	CommitCount int
+	LOCAdded    int64
+	LOCDeleted  int64
 }
 
 type AuthorStats struct {
@@ -35,6 +54,7 @@ type AuthorStats struct {
 	MaxVelocity float64
 	FirstCommit time.Time
 	LastCommit  time.Time
+	Velocities  []float64
 }
 
 type Percentiles struct {
@@ -57,6 +77,7 @@ func CalculateStats(commits []*git.Commit, pairs []*git.CommitPair) *RepositoryS
 		TotalCommits:     len(commits),
 		TotalCommitPairs: len(pairs),
 		Authors:          make(map[string]*AuthorStats),
+		Languages:        make(map[string]*LanguageStats),
 	}
 
 	if len(commits) == 0 {
@@ -89,12 +110,32 @@ func CalculateStats(commits []*git.Commit, pairs []*git.CommitPair) *RepositoryS
 	velocities := make([]float64, 0, len(pairs))
 
 	for _, pair := range pairs {
+		if pair.IsMerge {
+			stats.MergeCommitPairs++
+			if pair.MergeStats != nil {
+				stats.MergeLOCAdded += pair.MergeStats.Additions
+				stats.MergeLOCDeleted += pair.MergeStats.Deletions
+			}
+			continue
+		}
+
 		stats.TotalLOCAdded += pair.Stats.Additions
 		stats.TotalLOCDeleted += pair.Stats.Deletions
 
 		stats.UnfilteredLOCAdded += pair.Stats.TotalAdditions
 		stats.UnfilteredLOCDeleted += pair.Stats.TotalDeletions
 
+		for name, lang := range pair.Stats.Languages {
+			ls, ok := stats.Languages[name]
+			if !ok {
+				ls = &LanguageStats{Name: name}
+				stats.Languages[name] = ls
+			}
+			ls.CommitCount++
+			ls.LOCAdded += lang.Additions
+			ls.LOCDeleted += lang.Deletions
+		}
+
 		hasFilteredChanges := pair.Stats.Additions > 0 || pair.Stats.Deletions > 0
 
 		if hasFilteredChanges {
@@ -132,21 +173,14 @@ func CalculateStats(commits []*git.Commit, pairs []*git.CommitPair) *RepositoryS
 		}
 	}
 
-	if len(velocities) > 0 {
-		sum := 0.0
-		for _, v := range velocities {
-			sum += v
-		}
-		stats.AverageVelocity = sum / float64(len(velocities))
-		stats.MedianVelocity = calculateMedian(velocities)
-		stats.VelocityPercentile = calculatePercentiles(velocities)
-	}
+	stats.Velocities = velocities
+	stats.updateVelocities()
 
 	for email, authorStats := range stats.Authors {
 		if authorStats.CommitCount > 0 {
 			authorVelocities := make([]float64, 0)
 			for _, pair := range pairs {
-				if pair.Current.Email == email {
+				if pair.Current.Email == email && !pair.IsMerge {
 					if pair.Stats.Additions == 0 && pair.Stats.Deletions == 0 {
 						continue
 					}
@@ -158,12 +192,9 @@ func CalculateStats(commits []*git.Commit, pairs []*git.CommitPair) *RepositoryS
 				}
 			}
 
+			authorStats.Velocities = authorVelocities
 			if len(authorVelocities) > 0 {
-				sum := 0.0
-				for _, v := range authorVelocities {
-					sum += v
-				}
-				authorStats.AvgVelocity = sum / float64(len(authorVelocities))
+				authorStats.AvgVelocity = average(authorVelocities)
 			}
 		}
 	}
@@ -171,6 +202,109 @@ func CalculateStats(commits []*git.Commit, pairs []*git.CommitPair) *RepositoryS
 	return stats
 }
 
+// MergeStats combines the stats of two disjoint sets of commits, such as a
+// previous analysis and the commits added since.
+func MergeStats(previous, current *RepositoryStats) *RepositoryStats {
+	if previous == nil {
+		return current
+	}
+	if current == nil {
+		return previous
+	}
+
+	merged := &RepositoryStats{
+		TotalCommits:         previous.TotalCommits + current.TotalCommits,
+		TotalCommitPairs:     previous.TotalCommitPairs + current.TotalCommitPairs,
+		Authors:              make(map[string]*AuthorStats),
+		Languages:            make(map[string]*LanguageStats),
+		FirstCommit:          earliest(previous.FirstCommit, current.FirstCommit),
+		LastCommit:           latest(previous.LastCommit, current.LastCommit),
+		TotalLOCAdded:        previous.TotalLOCAdded + current.TotalLOCAdded,
+		TotalLOCDeleted:      previous.TotalLOCDeleted + current.TotalLOCDeleted,
+		UnfilteredLOCAdded:   previous.UnfilteredLOCAdded + current.UnfilteredLOCAdded,
+		UnfilteredLOCDeleted: previous.UnfilteredLOCDeleted + current.UnfilteredLOCDeleted,
+		MergeCommitPairs:     previous.MergeCommitPairs + current.MergeCommitPairs,
+		MergeLOCAdded:        previous.MergeLOCAdded + current.MergeLOCAdded,
+		MergeLOCDeleted:      previous.MergeLOCDeleted + current.MergeLOCDeleted,
+		Velocities:           append(append([]float64{}, previous.Velocities...), current.Velocities...),
+	}
+	merged.TimeSpan = merged.LastCommit.Sub(merged.FirstCommit)
+	merged.updateVelocities()
+
+	for _, authors := range []map[string]*AuthorStats{previous.Authors, current.Authors} {
+		for key, a := range authors {
+			m, ok := merged.Authors[key]
+			if !ok {
+				copied := *a
+				copied.Velocities = append([]float64{}, a.Velocities...)
+				merged.Authors[key] = &copied
+				continue
+			}
+
+			m.CommitCount += a.CommitCount
+			m.LOCAdded += a.LOCAdded
+			m.LOCDeleted += a.LOCDeleted
+			m.MaxVelocity = math.Max(m.MaxVelocity, a.MaxVelocity)
+			m.FirstCommit = earliest(m.FirstCommit, a.FirstCommit)
+			m.LastCommit = latest(m.LastCommit, a.LastCommit)
+			m.Velocities = append(m.Velocities, a.Velocities...)
+			if len(m.Velocities) > 0 {
+				m.AvgVelocity = average(m.Velocities)
+			}
+		}
+	}
+	merged.UniqueAuthors = len(merged.Authors)
+
+	for _, languages := range []map[string]*LanguageStats{previous.Languages, current.Languages} {
+		for name, l := range languages {
+			m, ok := merged.Languages[name]
+			if !ok {
+				copied := *l
+				merged.Languages[name] = &copied
+				continue
+			}
+
+			m.CommitCount += l.CommitCount
+			m.LOCAdded += l.LOCAdded
+			m.LOCDeleted += l.LOCDeleted
+		}
+	}
+
+	return merged
+}
+
+func (s *RepositoryStats) updateVelocities() {
+	if len(s.Velocities) == 0 {
+		return
+	}
+
+	s.AverageVelocity = average(s.Velocities)
+	s.MedianVelocity = calculateMedian(s.Velocities)
+	s.VelocityPercentile = calculatePercentiles(s.Velocities)
+}
+
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.IsZero() || b.After(a) {
+		return b
+	}
+	return a
+}
+
 func calculateMedian(values []float64) float64 {
 	if len(values) == 0 {
 		return 0
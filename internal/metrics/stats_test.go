@@ -230,6 +230,179 @@ func TestCalculateStats(t *testing.T) {
 			t.Errorf("MedianVelocity = %f, want 0 (should skip zero-change commits)", stats.MedianVelocity)
 		}
 	})
+
+	t.Run("merge pairs are tracked separately", func(t *testing.T) {
+		commits := []*git.Commit{
+			{Hash: "abc123", Email: "john@example.com", Timestamp: now},
+			{Hash: "def456", Email: "john@example.com", Timestamp: now.Add(10 * time.Minute)},
+			{Hash: "merge1", Email: "john@example.com", Timestamp: now.Add(20 * time.Minute)},
+		}
+
+		pairs := []*git.CommitPair{
+			{
+				Previous:  commits[0],
+				Current:   commits[1],
+				TimeDelta: 10 * time.Minute,
+				Stats:     &git.DiffStats{Additions: 100},
+			},
+			{
+				Previous:   commits[1],
+				Current:    commits[2],
+				TimeDelta:  10 * time.Minute,
+				Stats:      &git.DiffStats{Additions: 1000},
+				IsMerge:    true,
+				MergeStats: &git.DiffStats{Additions: 7, Deletions: 3},
+			},
+		}
+
+		stats := CalculateStats(commits, pairs)
+
+		if stats.TotalLOCAdded != 100 {
+			t.Errorf("TotalLOCAdded = %d, want 100 (merge first-parent diff excluded)", stats.TotalLOCAdded)
+		}
+		if stats.MergeCommitPairs != 1 {
+			t.Errorf("MergeCommitPairs = %d, want 1", stats.MergeCommitPairs)
+		}
+		if stats.MergeLOCAdded != 7 || stats.MergeLOCDeleted != 3 {
+			t.Errorf("Merge LOC = +%d/-%d, want +7/-3", stats.MergeLOCAdded, stats.MergeLOCDeleted)
+		}
+		if stats.AverageVelocity != 10 {
+			t.Errorf("AverageVelocity = %f, want 10", stats.AverageVelocity)
+		}
+		if stats.Authors["john@example.com"].CommitCount != 1 {
+			t.Errorf("CommitCount = %d, want 1", stats.Authors["john@example.com"].CommitCount)
+		}
+	})
+
+	t.Run("language statistics", func(t *testing.T) {
+		commits := []*git.Commit{
+			{Hash: "abc123", Email: "john@example.com", Timestamp: now},
+			{Hash: "def456", Email: "john@example.com", Timestamp: now.Add(10 * time.Minute)},
+			{Hash: "ghi789", Email: "john@example.com", Timestamp: now.Add(20 * time.Minute)},
+		}
+
+		pairs := []*git.CommitPair{
+			{
+				Previous:  commits[0],
+				Current:   commits[1],
+				TimeDelta: 10 * time.Minute,
+				Stats: &git.DiffStats{Additions: 120, Deletions: 5, Languages: map[string]git.LanguageStats{
+					"Go":       {Additions: 100, Deletions: 5},
+					"Markdown": {Additions: 20},
+				}},
+			},
+			{
+				Previous:  commits[1],
+				Current:   commits[2],
+				TimeDelta: 10 * time.Minute,
+				Stats: &git.DiffStats{Additions: 30, Languages: map[string]git.LanguageStats{
+					"Go": {Additions: 30},
+				}},
+			},
+		}
+
+		stats := CalculateStats(commits, pairs)
+
+		goStats := stats.Languages["Go"]
+		if goStats == nil || goStats.CommitCount != 2 || goStats.LOCAdded != 130 || goStats.LOCDeleted != 5 {
+			t.Errorf("Languages[Go] = %+v, want 2 commits +130/-5", goStats)
+		}
+		md := stats.Languages["Markdown"]
+		if md == nil || md.CommitCount != 1 || md.LOCAdded != 20 {
+			t.Errorf("Languages[Markdown] = %+v, want 1 commit +20", md)
+		}
+	})
+}
+
+func TestMergeStats(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	commit := func(hash, email string, offset time.Duration) *git.Commit {
+		return &git.Commit{Hash: hash, Author: email, Email: email, Timestamp: base.Add(offset)}
+	}
+	pair := func(prev, cur *git.Commit, additions int64) *git.CommitPair {
+		return &git.CommitPair{
+			Previous:  prev,
+			Current:   cur,
+			TimeDelta: cur.Timestamp.Sub(prev.Timestamp),
+			Stats:     &git.DiffStats{Additions: additions, Deletions: 1, TotalAdditions: additions, TotalDeletions: 1},
+		}
+	}
+
+	c1 := commit("c1", "alice@example.com", 0)
+	c2 := commit("c2", "alice@example.com", 10*time.Minute)
+	c3 := commit("c3", "bob@example.com", 20*time.Minute)
+	c4 := commit("c4", "alice@example.com", 25*time.Minute)
+	c5 := commit("c5", "carol@example.com", 45*time.Minute)
+
+	oldCommits := []*git.Commit{c2, c1}
+	oldPairs := []*git.CommitPair{pair(c1, c2, 100)}
+	newCommits := []*git.Commit{c5, c4, c3}
+	newPairs := []*git.CommitPair{pair(c2, c3, 50), pair(c3, c4, 400), pair(c4, c5, 20)}
+
+	merged := MergeStats(CalculateStats(oldCommits, oldPairs), CalculateStats(newCommits, newPairs))
+	want := CalculateStats(append(newCommits, oldCommits...), append(oldPairs, newPairs...))
+
+	if merged.TotalCommits != want.TotalCommits || merged.TotalCommitPairs != want.TotalCommitPairs {
+		t.Errorf("commits/pairs = %d/%d, want %d/%d", merged.TotalCommits, merged.TotalCommitPairs, want.TotalCommits, want.TotalCommitPairs)
+	}
+	if merged.UniqueAuthors != want.UniqueAuthors {
+		t.Errorf("UniqueAuthors = %d, want %d", merged.UniqueAuthors, want.UniqueAuthors)
+	}
+	if merged.TimeSpan != want.TimeSpan || !merged.FirstCommit.Equal(want.FirstCommit) || !merged.LastCommit.Equal(want.LastCommit) {
+		t.Errorf("time span = %v (%v..%v), want %v", merged.TimeSpan, merged.FirstCommit, merged.LastCommit, want.TimeSpan)
+	}
+	if merged.TotalLOCAdded != want.TotalLOCAdded || merged.UnfilteredLOCDeleted != want.UnfilteredLOCDeleted {
+		t.Errorf("LOC = +%d / -%d unfiltered, want +%d / -%d", merged.TotalLOCAdded, merged.UnfilteredLOCDeleted, want.TotalLOCAdded, want.UnfilteredLOCDeleted)
+	}
+	if abs(merged.AverageVelocity-want.AverageVelocity) > 0.0001 || abs(merged.MedianVelocity-want.MedianVelocity) > 0.0001 {
+		t.Errorf("velocity avg/median = %.2f/%.2f, want %.2f/%.2f", merged.AverageVelocity, merged.MedianVelocity, want.AverageVelocity, want.MedianVelocity)
+	}
+	if abs(merged.VelocityPercentile.P90-want.VelocityPercentile.P90) > 0.0001 {
+		t.Errorf("P90 = %.2f, want %.2f", merged.VelocityPercentile.P90, want.VelocityPercentile.P90)
+	}
+
+	alice, wantAlice := merged.Authors["alice@example.com"], want.Authors["alice@example.com"]
+	if alice.CommitCount != wantAlice.CommitCount || alice.LOCAdded != wantAlice.LOCAdded ||
+		abs(alice.AvgVelocity-wantAlice.AvgVelocity) > 0.0001 || alice.MaxVelocity != wantAlice.MaxVelocity {
+		t.Errorf("alice = %+v, want %+v", alice, wantAlice)
+	}
+
+	t.Run("languages", func(t *testing.T) {
+		withLanguage := func(p *git.CommitPair, name string) *git.CommitPair {
+			stats := *p.Stats
+			stats.Languages = map[string]git.LanguageStats{name: {Additions: stats.Additions, Deletions: stats.Deletions}}
+			return &git.CommitPair{Previous: p.Previous, Current: p.Current, TimeDelta: p.TimeDelta, Stats: &stats}
+		}
+		oldLang := []*git.CommitPair{withLanguage(oldPairs[0], "Go")}
+		newLang := []*git.CommitPair{withLanguage(newPairs[0], "Go"), withLanguage(newPairs[1], "YAML")}
+
+		merged := MergeStats(CalculateStats(oldCommits, oldLang), CalculateStats(newCommits, newLang))
+		want := CalculateStats(append(newCommits, oldCommits...), append(oldLang, newLang...))
+		if len(merged.Languages) != len(want.Languages) {
+			t.Fatalf("len(Languages) = %d, want %d", len(merged.Languages), len(want.Languages))
+		}
+		for name, w := range want.Languages {
+			if got := merged.Languages[name]; got == nil || *got != *w {
+				t.Errorf("Languages[%s] = %+v, want %+v", name, got, w)
+			}
+		}
+	})
+
+	t.Run("nil sides", func(t *testing.T) {
+		stats := CalculateStats(oldCommits, oldPairs)
+		if MergeStats(nil, stats) != stats || MergeStats(stats, nil) != stats {
+			t.Error("merging with nil should return the other side")
+		}
+	})
+
+	t.Run("inputs are not modified", func(t *testing.T) {
+		previous := CalculateStats(oldCommits, oldPairs)
+		_ = MergeStats(previous, CalculateStats(newCommits, newPairs))
+		if previous.Authors["alice@example.com"].CommitCount != 1 {
+			t.Errorf("previous alice CommitCount = %d, want 1", previous.Authors["alice@example.com"].CommitCount)
+		}
+	})
 }
 
 func TestCalculateMedian(t *testing.T) {
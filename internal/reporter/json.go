@@ -3,29 +3,74 @@ package reporter
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/anisimov-anthony/vibector/internal/detector"
+	"github.com/anisimov-anthony/vibector/internal/metrics"
 )
 
 type JSONReporter struct{}
 
 type JSONReport struct {
+	HistoryTruncated  bool                   `json:"history_truncated,omitempty"`
+	OnlyUnsigned      bool                   `json:"only_unsigned,omitempty"`
+	ShallowCommits    int                    `json:"shallow_boundary_commits,omitempty"`
+	Incremental       *JSONIncremental       `json:"incremental,omitempty"`
 	Statistics        JSONStats              `json:"statistics"`
 	Thresholds        JSONThresholds         `json:"thresholds"`
 	SuspiciousCount   int                    `json:"suspicious_count"`
 	SuspiciousCommits []JSONSuspiciousCommit `json:"suspicious_commits"`
+	Submodules        []JSONSubmodule        `json:"submodules,omitempty"`
+}
+
+type JSONSubmodule struct {
+	Name              string                 `json:"name"`
+	Path              string                 `json:"path"`
+	HistoryTruncated  bool                   `json:"history_truncated,omitempty"`
+	ShallowCommits    int                    `json:"shallow_boundary_commits,omitempty"`
+	Statistics        JSONStats              `json:"statistics"`
+	SuspiciousCount   int                    `json:"suspicious_count"`
+	SuspiciousCommits []JSONSuspiciousCommit `json:"suspicious_commits"`
 }
 
 type JSONStats struct {
-	TotalCommits         int              `json:"total_commits"`
-	CommitPairs          int              `json:"commit_pairs"`
-	UniqueAuthors        int              `json:"unique_authors"`
-	TimeSpanSeconds      float64          `json:"time_span_seconds"`
-	TotalLOCAdded        int64            `json:"total_loc_added_filtered"`
-	TotalLOCDeleted      int64            `json:"total_loc_deleted_filtered"`
-	UnfilteredLOCAdded   int64            `json:"total_loc_added_unfiltered"`
-	UnfilteredLOCDeleted int64            `json:"total_loc_deleted_unfiltered"`
-	AverageVelocity      float64          `json:"average_velocity_loc_per_min"`
-	MedianVelocity       float64          `json:"median_velocity_loc_per_min"`
-	VelocityPercentiles  *JSONPercentiles `json:"velocity_percentiles,omitempty"`
+	TotalCommits         int                          `json:"total_commits"`
+	CommitPairs          int                          `json:"commit_pairs"`
+	UniqueAuthors        int                          `json:"unique_authors"`
+	TimeSpanSeconds      float64                      `json:"time_span_seconds"`
+	TotalLOCAdded        int64                        `json:"total_loc_added_filtered"`
+	TotalLOCDeleted      int64                        `json:"total_loc_deleted_filtered"`
+	UnfilteredLOCAdded   int64                        `json:"total_loc_added_unfiltered"`
+	UnfilteredLOCDeleted int64                        `json:"total_loc_deleted_unfiltered"`
+	MergeCommitPairs     int                          `json:"merge_commit_pairs,omitempty"`
+	MergeLOCAdded        int64                        `json:"merge_loc_added,omitempty"`
+	MergeLOCDeleted      int64                        `json:"merge_loc_deleted,omitempty"`
+	AverageVelocity      float64                      `json:"average_velocity_loc_per_min"`
+	MedianVelocity       float64                      `json:"median_velocity_loc_per_min"`
+	VelocityPercentiles  *JSONPercentiles             `json:"velocity_percentiles,omitempty"`
+	Languages            map[string]JSONLanguageStats `json:"languages,omitempty"`
+}
+
+type JSONLanguageStats struct {
+	Commits   int   `json:"commits"`
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
+}
+
+type JSONLanguageLines struct {
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
+}
+
+type JSONLanguageThresholds struct {
+	SuspiciousAdditions int64   `json:"suspicious_additions"`
+	SuspiciousDeletions int64   `json:"suspicious_deletions"`
+	MaxAdditionsPerMin  float64 `json:"max_additions_per_min"`
+	MaxDeletionsPerMin  float64 `json:"max_deletions_per_min"`
+}
+
+type JSONIncremental struct {
+	NewCommits  int    `json:"new_commits"`
+	PreviousRun string `json:"previous_run,omitempty"`
 }
 
 type JSONPercentiles struct {
@@ -37,89 +82,131 @@ type JSONPercentiles struct {
 }
 
 type JSONThresholds struct {
-	SuspiciousAdditions int64   `json:"suspicious_additions"`
-	SuspiciousDeletions int64   `json:"suspicious_deletions"`
-	MaxAdditionsPerMin  float64 `json:"max_additions_per_min"`
-	MaxDeletionsPerMin  float64 `json:"max_deletions_per_min"`
-	MinTimeDeltaSeconds int64   `json:"min_time_delta_seconds"`
+	SuspiciousAdditions int64                             `json:"suspicious_additions"`
+	SuspiciousDeletions int64                             `json:"suspicious_deletions"`
+	MaxAdditionsPerMin  float64                           `json:"max_additions_per_min"`
+	MaxDeletionsPerMin  float64                           `json:"max_deletions_per_min"`
+	MinTimeDeltaSeconds int64                             `json:"min_time_delta_seconds"`
+	MaxTimestampSkew    int64                             `json:"max_timestamp_skew_seconds"`
+	Languages           map[string]JSONLanguageThresholds `json:"languages,omitempty"`
+	AISignatures        []string                          `json:"ai_signatures"`
 }
 
 type JSONSuspiciousCommit struct {
-	Hash                string   `json:"hash"`
-	Author              string   `json:"author"`
-	Email               string   `json:"email"`
-	Timestamp           string   `json:"timestamp"`
-	Message             string   `json:"message"`
-	Additions           int64    `json:"additions_filtered"`
-	Deletions           int64    `json:"deletions_filtered"`
-	TotalAdditions      int64    `json:"additions_total"`
-	TotalDeletions      int64    `json:"deletions_total"`
-	FilesChanged        int      `json:"files_changed_filtered"`
-	FilesChangedTotal   int      `json:"files_changed_total"`
-	TimeDelta           float64  `json:"time_delta_seconds"`
-	AdditionVelocityMin float64  `json:"addition_velocity_per_min"`
-	DeletionVelocityMin float64  `json:"deletion_velocity_per_min"`
-	Reasons             []string `json:"reasons"`
+	Hash                string                       `json:"hash"`
+	Author              string                       `json:"author"`
+	Email               string                       `json:"email"`
+	Timestamp           string                       `json:"timestamp"`
+	AuthorTimestamp     string                       `json:"author_timestamp,omitempty"`
+	Committer           string                       `json:"committer,omitempty"`
+	CommitterEmail      string                       `json:"committer_email,omitempty"`
+	CommitterTimestamp  string                       `json:"committer_timestamp,omitempty"`
+	Message             string                       `json:"message"`
+	Refs                []string                     `json:"refs,omitempty"`
+	Additions           int64                        `json:"additions_filtered"`
+	Deletions           int64                        `json:"deletions_filtered"`
+	TotalAdditions      int64                        `json:"additions_total"`
+	TotalDeletions      int64                        `json:"deletions_total"`
+	FilesChanged        int                          `json:"files_changed_filtered"`
+	FilesChangedTotal   int                          `json:"files_changed_total"`
+	Renames             int                          `json:"renames,omitempty"`
+	Copies              int                          `json:"copies,omitempty"`
+	FormattingAdditions int64                        `json:"formatting_additions,omitempty"`
+	FormattingDeletions int64                        `json:"formatting_deletions,omitempty"`
+	BinaryFiles         int                          `json:"binary_files,omitempty"`
+	BinarySizeDelta     int64                        `json:"binary_size_delta_bytes,omitempty"`
+	SubmoduleUpdates    int                          `json:"submodule_updates,omitempty"`
+	TimeDelta           float64                      `json:"time_delta_seconds"`
+	AdditionVelocityMin float64                      `json:"addition_velocity_per_min"`
+	DeletionVelocityMin float64                      `json:"deletion_velocity_per_min"`
+	IsMerge             bool                         `json:"is_merge,omitempty"`
+	MergeAdditions      int64                        `json:"merge_additions,omitempty"`
+	MergeDeletions      int64                        `json:"merge_deletions,omitempty"`
+	Languages           map[string]JSONLanguageLines `json:"languages,omitempty"`
+	TopFiles            []JSONFileStats              `json:"top_files,omitempty"`
+	AISignature         string                       `json:"ai_signature,omitempty"`
+	Signed              bool                         `json:"signed"`
+	Signature           *JSONSignature               `json:"signature,omitempty"`
+	Reasons             []string                     `json:"reasons"`
+}
+
+type JSONSignature struct {
+	Format string `json:"format"`
+	KeyID  string `json:"key_id,omitempty"`
+	Status string `json:"status"`
+	Signer string `json:"signer,omitempty"`
+}
+
+type JSONFileStats struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Language  string `json:"language,omitempty"`
+	Additions int64  `json:"additions"`
+	Deletions int64  `json:"deletions"`
+	Excluded  bool   `json:"excluded,omitempty"`
+	Submodule bool   `json:"submodule,omitempty"`
 }
 
 func (r *JSONReporter) Generate(data *ReportData) (string, error) {
 	report := JSONReport{
-		Statistics: JSONStats{
-			TotalCommits:         data.Stats.TotalCommits,
-			CommitPairs:          data.Stats.TotalCommitPairs,
-			UniqueAuthors:        data.Stats.UniqueAuthors,
-			TimeSpanSeconds:      data.Stats.TimeSpan.Seconds(),
-			TotalLOCAdded:        data.Stats.TotalLOCAdded,
-			TotalLOCDeleted:      data.Stats.TotalLOCDeleted,
-			UnfilteredLOCAdded:   data.Stats.UnfilteredLOCAdded,
-			UnfilteredLOCDeleted: data.Stats.UnfilteredLOCDeleted,
-			AverageVelocity:      data.Stats.AverageVelocity,
-			MedianVelocity:       data.Stats.MedianVelocity,
-		},
+		Statistics: newJSONStats(data.Stats),
 		Thresholds: JSONThresholds{
 			SuspiciousAdditions: data.Thresholds.SuspiciousAdditions,
 			SuspiciousDeletions: data.Thresholds.SuspiciousDeletions,
 			MaxAdditionsPerMin:  data.Thresholds.MaxAdditionsPerMin,
 			MaxDeletionsPerMin:  data.Thresholds.MaxDeletionsPerMin,
 			MinTimeDeltaSeconds: data.Thresholds.MinTimeDeltaSeconds,
+			MaxTimestampSkew:    data.Thresholds.MaxTimestampSkewSeconds,
+			AISignatures:        data.Thresholds.Signatures(),
 		},
+		OnlyUnsigned:      data.OnlyUnsigned,
 		SuspiciousCount:   len(data.Suspicious),
 		SuspiciousCommits: make([]JSONSuspiciousCommit, len(data.Suspicious)),
 	}
 
-	if data.Stats.VelocityPercentile != nil {
-		report.Statistics.VelocityPercentiles = &JSONPercentiles{
-			P50: data.Stats.VelocityPercentile.P50,
-			P75: data.Stats.VelocityPercentile.P75,
-			P90: data.Stats.VelocityPercentile.P90,
-			P95: data.Stats.VelocityPercentile.P95,
-			P99: data.Stats.VelocityPercentile.P99,
+	for name, l := range data.Thresholds.Languages {
+		if report.Thresholds.Languages == nil {
+			report.Thresholds.Languages = make(map[string]JSONLanguageThresholds)
+		}
+		report.Thresholds.Languages[name] = JSONLanguageThresholds{
+			SuspiciousAdditions: l.SuspiciousAdditions,
+			SuspiciousDeletions: l.SuspiciousDeletions,
+			MaxAdditionsPerMin:  l.MaxAdditionsPerMin,
+			MaxDeletionsPerMin:  l.MaxDeletionsPerMin,
 		}
 	}
 
-	for i, s := range data.Suspicious {
-		commit := JSONSuspiciousCommit{
-			Hash:              s.Pair.Current.Hash,
-			Author:            s.Pair.Current.Author,
-			Email:             s.Pair.Current.Email,
-			Timestamp:         s.Pair.Current.Timestamp.Format(time.RFC3339),
-			Message:           s.Pair.Current.Message,
-			Additions:         s.Pair.Stats.Additions,
-			Deletions:         s.Pair.Stats.Deletions,
-			TotalAdditions:    s.Pair.Stats.TotalAdditions,
-			TotalDeletions:    s.Pair.Stats.TotalDeletions,
-			FilesChanged:      s.Pair.Stats.FilesChanged,
-			FilesChangedTotal: s.Pair.Stats.FilesChangedTotal,
-			TimeDelta:         s.Pair.TimeDelta.Seconds(),
-			Reasons:           s.Reasons,
+	if data.ShallowCommits > 0 {
+		report.HistoryTruncated = true
+		report.ShallowCommits = data.ShallowCommits
+	}
+
+	if data.Incremental != nil {
+		report.Incremental = &JSONIncremental{NewCommits: data.Incremental.NewCommits}
+		if !data.Incremental.PreviousRun.IsZero() {
+			report.Incremental.PreviousRun = data.Incremental.PreviousRun.Format(time.RFC3339)
 		}
-		if s.AdditionVelocity != nil {
-			commit.AdditionVelocityMin = s.AdditionVelocity.LOCPerMinute
+	}
+
+	for i, s := range data.Suspicious {
+		report.SuspiciousCommits[i] = newJSONSuspiciousCommit(s)
+	}
+
+	for _, sub := range data.Submodules {
+		submodule := JSONSubmodule{
+			Name:              sub.Name,
+			Path:              sub.Path,
+			HistoryTruncated:  sub.ShallowCommits > 0,
+			ShallowCommits:    sub.ShallowCommits,
+			Statistics:        newJSONStats(sub.Stats),
+			SuspiciousCount:   len(sub.Suspicious),
+			SuspiciousCommits: make([]JSONSuspiciousCommit, len(sub.Suspicious)),
 		}
-		if s.DeletionVelocity != nil {
-			commit.DeletionVelocityMin = s.DeletionVelocity.LOCPerMinute
+		for i, s := range sub.Suspicious {
+			submodule.SuspiciousCommits[i] = newJSONSuspiciousCommit(s)
 		}
-		report.SuspiciousCommits[i] = commit
+		report.Submodules = append(report.Submodules, submodule)
 	}
 
 	bytes, err := json.MarshalIndent(report, "", "  ")
@@ -129,3 +216,119 @@ func (r *JSONReporter) Generate(data *ReportData) (string, error) {
 
 	return string(bytes), nil
 }
+
+func newJSONStats(stats *metrics.RepositoryStats) JSONStats {
+	result := JSONStats{
+		TotalCommits:         stats.TotalCommits,
+		CommitPairs:          stats.TotalCommitPairs,
+		UniqueAuthors:        stats.UniqueAuthors,
+		TimeSpanSeconds:      stats.TimeSpan.Seconds(),
+		TotalLOCAdded:        stats.TotalLOCAdded,
+		TotalLOCDeleted:      stats.TotalLOCDeleted,
+		UnfilteredLOCAdded:   stats.UnfilteredLOCAdded,
+		UnfilteredLOCDeleted: stats.UnfilteredLOCDeleted,
+		MergeCommitPairs:     stats.MergeCommitPairs,
+		MergeLOCAdded:        stats.MergeLOCAdded,
+		MergeLOCDeleted:      stats.MergeLOCDeleted,
+		AverageVelocity:      stats.AverageVelocity,
+		MedianVelocity:       stats.MedianVelocity,
+	}
+
+	for name, lang := range stats.Languages {
+		if result.Languages == nil {
+			result.Languages = make(map[string]JSONLanguageStats)
+		}
+		result.Languages[name] = JSONLanguageStats{
+			Commits:   lang.CommitCount,
+			Additions: lang.LOCAdded,
+			Deletions: lang.LOCDeleted,
+		}
+	}
+
+	if stats.VelocityPercentile != nil {
+		result.VelocityPercentiles = &JSONPercentiles{
+			P50: stats.VelocityPercentile.P50,
+			P75: stats.VelocityPercentile.P75,
+			P90: stats.VelocityPercentile.P90,
+			P95: stats.VelocityPercentile.P95,
+			P99: stats.VelocityPercentile.P99,
+		}
+	}
+
+	return result
+}
+
+func newJSONSuspiciousCommit(s *detector.SuspiciousCommit) JSONSuspiciousCommit {
+	commit := JSONSuspiciousCommit{
+		Hash:                s.Pair.Current.Hash,
+		Author:              s.Pair.Current.Author,
+		Email:               s.Pair.Current.Email,
+		Timestamp:           s.Pair.Current.Timestamp.Format(time.RFC3339),
+		Message:             s.Pair.Current.Message,
+		Refs:                s.Pair.Current.Refs,
+		Additions:           s.Pair.Stats.Additions,
+		Deletions:           s.Pair.Stats.Deletions,
+		TotalAdditions:      s.Pair.Stats.TotalAdditions,
+		TotalDeletions:      s.Pair.Stats.TotalDeletions,
+		FilesChanged:        s.Pair.Stats.FilesChanged,
+		FilesChangedTotal:   s.Pair.Stats.FilesChangedTotal,
+		Renames:             s.Pair.Stats.Renames,
+		Copies:              s.Pair.Stats.Copies,
+		FormattingAdditions: s.Pair.Stats.FormattingAdditions,
+		FormattingDeletions: s.Pair.Stats.FormattingDeletions,
+		BinaryFiles:         s.Pair.Stats.BinaryFiles,
+		BinarySizeDelta:     s.Pair.Stats.BinarySizeDelta,
+		SubmoduleUpdates:    s.Pair.Stats.SubmoduleUpdates,
+		TimeDelta:           s.Pair.TimeDelta.Seconds(),
+		Reasons:             s.Reasons,
+		AISignature:         s.AISignature,
+	}
+	if sig := s.Pair.Current.Signature; sig != nil {
+		commit.Signed = true
+		commit.Signature = &JSONSignature{
+			Format: string(sig.Format),
+			KeyID:  sig.KeyID,
+			Status: string(sig.Status),
+			Signer: sig.Signer,
+		}
+	}
+	if !s.Pair.Current.AuthorTimestamp.IsZero() {
+		commit.AuthorTimestamp = s.Pair.Current.AuthorTimestamp.Format(time.RFC3339)
+	}
+	if !s.Pair.Current.CommitterTimestamp.IsZero() {
+		commit.Committer = s.Pair.Current.Committer
+		commit.CommitterEmail = s.Pair.Current.CommitterEmail
+		commit.CommitterTimestamp = s.Pair.Current.CommitterTimestamp.Format(time.RFC3339)
+	}
+	if s.AdditionVelocity != nil {
+		commit.AdditionVelocityMin = s.AdditionVelocity.LOCPerMinute
+	}
+	if s.DeletionVelocity != nil {
+		commit.DeletionVelocityMin = s.DeletionVelocity.LOCPerMinute
+	}
+	if s.Pair.IsMerge && s.Pair.MergeStats != nil {
+		commit.IsMerge = true
+		commit.MergeAdditions = s.Pair.MergeStats.Additions
+		commit.MergeDeletions = s.Pair.MergeStats.Deletions
+	}
+	for name, lang := range s.Pair.Stats.Languages {
+		if commit.Languages == nil {
+			commit.Languages = make(map[string]JSONLanguageLines)
+		}
+		commit.Languages[name] = JSONLanguageLines{Additions: lang.Additions, Deletions: lang.Deletions}
+	}
+	for _, f := range s.Pair.Stats.TopFiles(topFilesLimit) {
+		commit.TopFiles = append(commit.TopFiles, JSONFileStats{
+			Path:      f.Path,
+			OldPath:   f.OldPath,
+			Status:    string(f.Status),
+			Language:  f.Language,
+			Additions: f.Additions,
+			Deletions: f.Deletions,
+			Excluded:  f.Excluded,
+			Submodule: f.Submodule,
+		})
+	}
+
+	return commit
+}
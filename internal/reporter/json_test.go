@@ -373,4 +373,429 @@ func TestJSONReporter_Generate(t *testing.T) {
 			t.Errorf("Timestamp = %s, want %s", result.SuspiciousCommits[0].Timestamp, expectedTimestamp)
 		}
 	})
+
+	t.Run("merge commit fields", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:   &git.Commit{Hash: "prev123"},
+						Current:    &git.Commit{Hash: "merge123", Timestamp: now},
+						TimeDelta:  5 * time.Minute,
+						Stats:      &git.DiffStats{Additions: 900},
+						IsMerge:    true,
+						MergeStats: &git.DiffStats{Additions: 120, Deletions: 4},
+					},
+					Reasons: []string{"Merge introduces 120 additions not present in any parent (threshold: 100 lines)"},
+				},
+			},
+			Stats: &metrics.RepositoryStats{
+				MergeCommitPairs: 1,
+				MergeLOCAdded:    120,
+				MergeLOCDeleted:  4,
+			},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if result.Statistics.MergeCommitPairs != 1 || result.Statistics.MergeLOCAdded != 120 {
+			t.Errorf("merge statistics = %+v, want 1 merge with 120 additions", result.Statistics)
+		}
+		sc := result.SuspiciousCommits[0]
+		if !sc.IsMerge || sc.MergeAdditions != 120 || sc.MergeDeletions != 4 {
+			t.Errorf("merge commit = %+v, want is_merge with +120/-4", sc)
+		}
+	})
+
+	t.Run("rename and copy counts", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "rename123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, Renames: 2, Copies: 1},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		sc := result.SuspiciousCommits[0]
+		if sc.Renames != 2 || sc.Copies != 1 {
+			t.Errorf("renames/copies = %d/%d, want 2/1", sc.Renames, sc.Copies)
+		}
+	})
+
+	t.Run("formatting line counts", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "format123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, FormattingAdditions: 450, FormattingDeletions: 440},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		sc := result.SuspiciousCommits[0]
+		if sc.FormattingAdditions != 450 || sc.FormattingDeletions != 440 {
+			t.Errorf("formatting = %d/%d, want 450/440", sc.FormattingAdditions, sc.FormattingDeletions)
+		}
+	})
+
+	t.Run("binary file fields", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "binary123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, BinaryFiles: 2, BinarySizeDelta: -1024},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		sc := result.SuspiciousCommits[0]
+		if sc.BinaryFiles != 2 || sc.BinarySizeDelta != -1024 {
+			t.Errorf("binary = %d files / %d bytes, want 2 / -1024", sc.BinaryFiles, sc.BinarySizeDelta)
+		}
+	})
+
+	t.Run("top files", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "files123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats: &git.DiffStats{Additions: 500, Files: []git.FileStats{
+							{Path: "package-lock.json", Status: git.FileModified, Additions: 4000, Excluded: true},
+							{Path: "pkg/new.go", OldPath: "old.go", Status: git.FileModified, Additions: 500, Deletions: 3},
+						}},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		files := result.SuspiciousCommits[0].TopFiles
+		want := []JSONFileStats{
+			{Path: "pkg/new.go", OldPath: "old.go", Status: "modified", Additions: 500, Deletions: 3},
+			{Path: "package-lock.json", Status: "modified", Additions: 4000, Excluded: true},
+		}
+		if len(files) != len(want) {
+			t.Fatalf("len(top_files) = %d, want %d", len(files), len(want))
+		}
+		for i := range want {
+			if files[i] != want[i] {
+				t.Errorf("top_files[%d] = %+v, want %+v", i, files[i], want[i])
+			}
+		}
+	})
+
+	t.Run("language fields", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "lang123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats: &git.DiffStats{
+							Additions: 400,
+							Languages: map[string]git.LanguageStats{"Go": {Additions: 400, Deletions: 2}},
+							Files:     []git.FileStats{{Path: "main.go", Status: git.FileModified, Language: "Go", Additions: 400, Deletions: 2}},
+						},
+					},
+					Reasons: []string{"Suspicious Go commit size: 400 additions (threshold: 200 lines)"},
+				},
+			},
+			Stats: &metrics.RepositoryStats{Languages: map[string]*metrics.LanguageStats{
+				"Go": {Name: "Go", CommitCount: 3, LOCAdded: 900, LOCDeleted: 40},
+			}},
+			Thresholds: &detector.Thresholds{Languages: map[string]detector.LanguageThresholds{
+				"go": {SuspiciousAdditions: 200},
+			}},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if got := result.Statistics.Languages["Go"]; got != (JSONLanguageStats{Commits: 3, Additions: 900, Deletions: 40}) {
+			t.Errorf("statistics.languages.Go = %+v", got)
+		}
+		if got := result.Thresholds.Languages["go"].SuspiciousAdditions; got != 200 {
+			t.Errorf("thresholds.languages.go.suspicious_additions = %d, want 200", got)
+		}
+		sc := result.SuspiciousCommits[0]
+		if got := sc.Languages["Go"]; got != (JSONLanguageLines{Additions: 400, Deletions: 2}) {
+			t.Errorf("languages.Go = %+v", got)
+		}
+		if len(sc.TopFiles) != 1 || sc.TopFiles[0].Language != "Go" {
+			t.Errorf("top_files = %+v, want main.go in Go", sc.TopFiles)
+		}
+	})
+
+	t.Run("ai signature fields", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "prev123"},
+						Current:   &git.Commit{Hash: "signed123", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 5},
+					},
+					Reasons:     []string{`AI tool signature in commit message (high confidence): "Generated with ChatGPT"`},
+					AISignature: "Generated with ChatGPT",
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if len(result.Thresholds.AISignatures) != len(detector.DefaultAISignatures) {
+			t.Errorf("len(ai_signatures) = %d, want the %d defaults", len(result.Thresholds.AISignatures), len(detector.DefaultAISignatures))
+		}
+		if got := result.SuspiciousCommits[0].AISignature; got != "Generated with ChatGPT" {
+			t.Errorf("ai_signature = %q, want the generated-with line", got)
+		}
+	})
+
+	t.Run("signature fields", func(t *testing.T) {
+		pair := func(hash string, sig *git.Signature) *detector.SuspiciousCommit {
+			return &detector.SuspiciousCommit{
+				Pair: &git.CommitPair{
+					Previous:  &git.Commit{Hash: "prev123"},
+					Current:   &git.Commit{Hash: hash, Timestamp: now, Signature: sig},
+					TimeDelta: 5 * time.Minute,
+					Stats:     &git.DiffStats{Additions: 500},
+				},
+				Reasons: []string{"Large commit"},
+			}
+		}
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				pair("unsigned123", nil),
+				pair("signed123", &git.Signature{Format: git.SignatureSSH, KeyID: "SHA256:abc", Status: git.SignatureUntrusted}),
+			},
+			Stats:        &metrics.RepositoryStats{},
+			Thresholds:   &detector.Thresholds{SuspiciousAdditions: 100},
+			OnlyUnsigned: true,
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if !result.OnlyUnsigned {
+			t.Error("only_unsigned = false, want true")
+		}
+		unsigned := result.SuspiciousCommits[0]
+		if unsigned.Signed || unsigned.Signature != nil {
+			t.Errorf("unsigned commit: signed = %v, signature = %+v", unsigned.Signed, unsigned.Signature)
+		}
+		signed := result.SuspiciousCommits[1]
+		want := JSONSignature{Format: "ssh", KeyID: "SHA256:abc", Status: "untrusted"}
+		if !signed.Signed || signed.Signature == nil || *signed.Signature != want {
+			t.Errorf("signed commit: signed = %v, signature = %+v, want %+v", signed.Signed, signed.Signature, want)
+		}
+	})
+
+	t.Run("submodules", func(t *testing.T) {
+		data := &ReportData{
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+			Submodules: []*SubmoduleReport{
+				{
+					Name:  "lib",
+					Path:  "vendor/lib",
+					Stats: &metrics.RepositoryStats{TotalCommits: 12, TotalLOCAdded: 900},
+					Suspicious: []*detector.SuspiciousCommit{
+						{
+							Pair: &git.CommitPair{
+								Previous:  &git.Commit{Hash: "libprev123"},
+								Current:   &git.Commit{Hash: "libbig123", Timestamp: now},
+								TimeDelta: time.Minute,
+								Stats:     &git.DiffStats{Additions: 890},
+							},
+							Reasons: []string{"Large commit"},
+						},
+					},
+					ShallowCommits: 1,
+				},
+			},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if len(result.Submodules) != 1 {
+			t.Fatalf("len(submodules) = %d, want 1", len(result.Submodules))
+		}
+		sub := result.Submodules[0]
+		if sub.Name != "lib" || sub.Path != "vendor/lib" || !sub.HistoryTruncated || sub.ShallowCommits != 1 {
+			t.Errorf("submodule = %+v", sub)
+		}
+		if sub.Statistics.TotalCommits != 12 || sub.Statistics.TotalLOCAdded != 900 {
+			t.Errorf("submodule statistics = %+v", sub.Statistics)
+		}
+		if sub.SuspiciousCount != 1 || sub.SuspiciousCommits[0].Hash != "libbig123" || sub.SuspiciousCommits[0].Additions != 890 {
+			t.Errorf("submodule suspicious commits = %+v", sub.SuspiciousCommits)
+		}
+	})
+
+	t.Run("truncated history", func(t *testing.T) {
+		data := &ReportData{
+			Stats:          &metrics.RepositoryStats{},
+			Thresholds:     &detector.Thresholds{SuspiciousAdditions: 100},
+			ShallowCommits: 2,
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+		if !result.HistoryTruncated || result.ShallowCommits != 2 {
+			t.Errorf("history_truncated = %v, shallow_boundary_commits = %d, want true and 2", result.HistoryTruncated, result.ShallowCommits)
+		}
+	})
+
+	t.Run("incremental run", func(t *testing.T) {
+		data := &ReportData{
+			Stats:      &metrics.RepositoryStats{TotalCommits: 120},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+			Incremental: &IncrementalRun{
+				NewCommits:  7,
+				PreviousRun: time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC),
+			},
+		}
+
+		reporter := &JSONReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		var result JSONReport
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("Generated JSON is invalid: %v", err)
+		}
+
+		if result.Incremental == nil || result.Incremental.NewCommits != 7 || result.Incremental.PreviousRun != "2024-03-01T02:00:00Z" {
+			t.Errorf("incremental = %+v, want 7 new commits since 2024-03-01T02:00:00Z", result.Incremental)
+		}
+	})
 }
@@ -0,0 +1,37 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anisimov-anthony/vibector/internal/detector"
+)
+
+// noteReviewedBy marks a note as reviewed when a line of it starts with it,
+// e.g. after git notes --ref=vibector append -m "Reviewed-by: Jane <jane@example.com>".
+const noteReviewedBy = "reviewed-by:"
+
+// FormatNote renders the git note vibector annotate attaches to a suspicious
+// commit.
+func FormatNote(s *detector.SuspiciousCommit) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Vibector: suspicious commit (score %d)\n\n", s.Score()))
+	for _, reason := range s.Reasons {
+		sb.WriteString(fmt.Sprintf("- %s\n", reason))
+	}
+
+	return sb.String()
+}
+
+// NoteReviewer returns who marked a note as reviewed with a Reviewed-by line.
+func NoteReviewer(note string) (string, bool) {
+	for _, line := range strings.Split(note, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) >= len(noteReviewedBy) && strings.EqualFold(line[:len(noteReviewedBy)], noteReviewedBy) {
+			return strings.TrimSpace(line[len(noteReviewedBy):]), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/anisimov-anthony/vibector/internal/detector"
+)
+
+func TestFormatNote(t *testing.T) {
+	s := &detector.SuspiciousCommit{
+		Reasons: []string{
+			"Suspicious commit size: 890 additions (threshold: 100 lines)",
+			"Commit too fast: 5s after previous (threshold: 60s)",
+		},
+	}
+
+	want := "Vibector: suspicious commit (score 2)\n\n" +
+		"- Suspicious commit size: 890 additions (threshold: 100 lines)\n" +
+		"- Commit too fast: 5s after previous (threshold: 60s)\n"
+	if got := FormatNote(s); got != want {
+		t.Errorf("FormatNote() = %q, want %q", got, want)
+	}
+}
+
+func TestNoteReviewer(t *testing.T) {
+	tests := []struct {
+		name         string
+		note         string
+		wantReviewer string
+		wantOK       bool
+	}{
+		{
+			name:   "not reviewed",
+			note:   "Vibector: suspicious commit (score 1)\n\n- reason\n",
+			wantOK: false,
+		},
+		{
+			name:         "appended reviewer",
+			note:         "Vibector: suspicious commit (score 1)\n\n- reason\n\nReviewed-by: Jane Doe <jane@example.com>\n",
+			wantReviewer: "Jane Doe <jane@example.com>",
+			wantOK:       true,
+		},
+		{
+			name:         "case insensitive",
+			note:         "reviewed-by: jane",
+			wantReviewer: "jane",
+			wantOK:       true,
+		},
+		{
+			name:   "mentioned in a reason",
+			note:   "- message lacks a Reviewed-by: trailer\n",
+			wantOK: false,
+		},
+		{
+			name:   "empty note",
+			note:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviewer, ok := NoteReviewer(tt.note)
+			if ok != tt.wantOK || reviewer != tt.wantReviewer {
+				t.Errorf("NoteReviewer() = (%q, %v), want (%q, %v)", reviewer, ok, tt.wantReviewer, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,15 +2,49 @@ package reporter
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/anisimov-anthony/vibector/internal/detector"
 	"github.com/anisimov-anthony/vibector/internal/metrics"
 )
 
+// topFilesLimit caps how many files are listed per suspicious commit.
+const topFilesLimit = 5
+
 type ReportData struct {
-	Suspicious []*detector.SuspiciousCommit
-	Stats      *metrics.RepositoryStats
-	Thresholds *detector.Thresholds
+	Suspicious  []*detector.SuspiciousCommit
+	Stats       *metrics.RepositoryStats
+	Thresholds  *detector.Thresholds
+	Incremental *IncrementalRun
+
+	// ShallowCommits is the number of shallow clone boundary commits that
+	// could not be analyzed. Non-zero means history was truncated.
+	ShallowCommits int
+
+	// OnlyUnsigned notes that commits with a good signature were left out of
+	// Suspicious.
+	OnlyUnsigned bool
+
+	// Submodules holds the analyses of the initialized submodules, made
+	// with the same thresholds.
+	Submodules []*SubmoduleReport
+}
+
+// SubmoduleReport is the analysis of one submodule's own history. Path is
+// relative to the superproject, also for nested submodules.
+type SubmoduleReport struct {
+	Name           string
+	Path           string
+	Suspicious     []*detector.SuspiciousCommit
+	Stats          *metrics.RepositoryStats
+	ShallowCommits int
+}
+
+// IncrementalRun describes an incremental analysis: Stats then cover every
+// run so far while Suspicious only lists commits added since the previous one.
+type IncrementalRun struct {
+	NewCommits  int
+	PreviousRun time.Time
 }
 
 type Reporter interface {
@@ -2,10 +2,13 @@ package reporter
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/anisimov-anthony/vibector/internal/detector"
+	"github.com/anisimov-anthony/vibector/internal/git"
+	"github.com/anisimov-anthony/vibector/internal/metrics"
 )
 
 type TextReporter struct{}
@@ -17,6 +20,23 @@ func (r *TextReporter) Generate(data *ReportData) (string, error) {
 	sb.WriteString("|            VIBECTOR ANALYSIS REPORT        |\n")
 	sb.WriteString("----------------------------------------------\n\n")
 
+	if data.ShallowCommits > 0 {
+		sb.WriteString(fmt.Sprintf("NOTE: History is truncated (shallow clone). %d boundary commit(s) have no parent\n", data.ShallowCommits))
+		sb.WriteString("and were not analyzed. Fetch more history (git fetch --unshallow) for full results.\n\n")
+	}
+
+	if data.Incremental != nil {
+		sb.WriteString("INCREMENTAL RUN\n")
+		sb.WriteString("---------------\n")
+		if data.Incremental.PreviousRun.IsZero() {
+			sb.WriteString("Previous Run:          none, full history analyzed\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("Previous Run:          %s\n", data.Incremental.PreviousRun.Format(time.RFC3339)))
+		}
+		sb.WriteString(fmt.Sprintf("New Commits:           %d\n", data.Incremental.NewCommits))
+		sb.WriteString("Statistics cover all runs, suspicious commits only the new ones.\n\n")
+	}
+
 	sb.WriteString("REPOSITORY STATISTICS\n")
 	sb.WriteString("---------------------\n")
 	sb.WriteString(fmt.Sprintf("Total Commits:         %d\n", data.Stats.TotalCommits))
@@ -32,6 +52,21 @@ func (r *TextReporter) Generate(data *ReportData) (string, error) {
 	sb.WriteString(fmt.Sprintf("  Additions:           %d lines\n", data.Stats.UnfilteredLOCAdded))
 	sb.WriteString(fmt.Sprintf("  Deletions:           %d lines\n\n", data.Stats.UnfilteredLOCDeleted))
 
+	if data.Stats.MergeCommitPairs > 0 {
+		sb.WriteString("Merge Commits (Lines Introduced by Merges):\n")
+		sb.WriteString(fmt.Sprintf("  Merges:              %d\n", data.Stats.MergeCommitPairs))
+		sb.WriteString(fmt.Sprintf("  Additions:           %d lines\n", data.Stats.MergeLOCAdded))
+		sb.WriteString(fmt.Sprintf("  Deletions:           %d lines\n\n", data.Stats.MergeLOCDeleted))
+	}
+
+	if len(data.Stats.Languages) > 0 {
+		sb.WriteString("Lines of Code by Language (Filtered):\n")
+		for _, lang := range languagesByChurn(data.Stats.Languages) {
+			sb.WriteString(fmt.Sprintf("  %-20s +%d / -%d lines in %d commit(s)\n", lang.Name+":", lang.LOCAdded, lang.LOCDeleted, lang.CommitCount))
+		}
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("VELOCITY STATISTICS\n")
 	sb.WriteString("-------------------\n")
 	sb.WriteString(fmt.Sprintf("Average Velocity:   %.2f LOC/min\n", data.Stats.AverageVelocity))
@@ -53,23 +88,87 @@ func (r *TextReporter) Generate(data *ReportData) (string, error) {
 	sb.WriteString(fmt.Sprintf("Max Additions/min:      %.2f additions/min (0 = disabled)\n", data.Thresholds.MaxAdditionsPerMin))
 	sb.WriteString(fmt.Sprintf("Max Deletions/min:      %.2f deletions/min (0 = disabled)\n", data.Thresholds.MaxDeletionsPerMin))
 	sb.WriteString(fmt.Sprintf("Min Time Delta:         %d seconds (0 = disabled)\n", data.Thresholds.MinTimeDeltaSeconds))
+	sb.WriteString(fmt.Sprintf("Max Timestamp Skew:     %d seconds (0 = disabled)\n", data.Thresholds.MaxTimestampSkewSeconds))
+	sb.WriteString(fmt.Sprintf("AI Signatures:          %d pattern(s) (0 = disabled)\n", len(data.Thresholds.Signatures())))
+	if len(data.Thresholds.Languages) > 0 {
+		names := make([]string, 0, len(data.Thresholds.Languages))
+		for name := range data.Thresholds.Languages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("Per Language (0 = disabled):\n")
+		for _, name := range names {
+			l := data.Thresholds.Languages[name]
+			sb.WriteString(fmt.Sprintf("  %-20s %d additions, %d deletions, %.2f additions/min, %.2f deletions/min\n",
+				name+":", l.SuspiciousAdditions, l.SuspiciousDeletions, l.MaxAdditionsPerMin, l.MaxDeletionsPerMin))
+		}
+	}
 	sb.WriteString("\n")
 
 	sb.WriteString("SUSPICIOUS COMMITS\n")
 	sb.WriteString("!!!!!!!!!!!!!!!!!!\n")
+	if data.OnlyUnsigned {
+		sb.WriteString("Commits whose signature verified against the keyring are not listed.\n")
+	}
+
+	writeSuspicious(&sb, data.Suspicious)
 
-	if len(data.Suspicious) == 0 {
+	if len(data.Submodules) > 0 {
+		if len(data.Suspicious) == 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("SUBMODULES\n")
+		sb.WriteString("----------\n")
+		sb.WriteString("Analyzed with the same thresholds as the superproject.\n\n")
+		for _, sub := range data.Submodules {
+			writeSubmodule(&sb, sub)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func writeSuspicious(sb *strings.Builder, suspicious []*detector.SuspiciousCommit) {
+	if len(suspicious) == 0 {
 		sb.WriteString("No suspicious commits detected.\n")
 	} else {
-		sb.WriteString(fmt.Sprintf("Found %d suspicious commit(s):\n\n", len(data.Suspicious)))
+		sb.WriteString(fmt.Sprintf("Found %d suspicious commit(s):\n\n", len(suspicious)))
 
-		for i, s := range data.Suspicious {
+		for i, s := range suspicious {
 			sb.WriteString(fmt.Sprintf("[%d] Commit: %s\n", i+1, s.Pair.Current.Hash[:7]))
 			sb.WriteString(fmt.Sprintf("    Author:          %s <%s>\n", s.Pair.Current.Author, s.Pair.Current.Email))
 			sb.WriteString(fmt.Sprintf("    Date:            %s\n", s.Pair.Current.Timestamp.Format(time.RFC3339)))
+			sb.WriteString(fmt.Sprintf("    Signature:       %s\n", formatSignature(s.Pair.Current.Signature)))
+			if !s.Pair.Current.CommitterTimestamp.IsZero() && !s.Pair.Current.CommitterTimestamp.Equal(s.Pair.Current.AuthorTimestamp) {
+				sb.WriteString(fmt.Sprintf("    Authored:        %s\n", s.Pair.Current.AuthorTimestamp.Format(time.RFC3339)))
+				sb.WriteString(fmt.Sprintf("    Committed:       %s by %s <%s>\n", s.Pair.Current.CommitterTimestamp.Format(time.RFC3339), s.Pair.Current.Committer, s.Pair.Current.CommitterEmail))
+			}
+			if len(s.Pair.Current.Refs) > 0 {
+				sb.WriteString(fmt.Sprintf("    Refs:            %s\n", strings.Join(s.Pair.Current.Refs, ", ")))
+			}
 			sb.WriteString(fmt.Sprintf("    Additions:       %d lines (filtered) / %d lines (total)\n", s.Pair.Stats.Additions, s.Pair.Stats.TotalAdditions))
 			sb.WriteString(fmt.Sprintf("    Deletions:       %d lines (filtered) / %d lines (total)\n", s.Pair.Stats.Deletions, s.Pair.Stats.TotalDeletions))
 			sb.WriteString(fmt.Sprintf("    Files Changed:   %d (filtered) / %d (total)\n", s.Pair.Stats.FilesChanged, s.Pair.Stats.FilesChangedTotal))
+			if s.Pair.Stats.BinaryFiles > 0 {
+				sb.WriteString(fmt.Sprintf("    Binary Files:    %d (%+d bytes)\n", s.Pair.Stats.BinaryFiles, s.Pair.Stats.BinarySizeDelta))
+			}
+			if s.Pair.Stats.FormattingAdditions > 0 || s.Pair.Stats.FormattingDeletions > 0 {
+				sb.WriteString(fmt.Sprintf("    Formatting:      %d additions / %d deletions only change whitespace\n", s.Pair.Stats.FormattingAdditions, s.Pair.Stats.FormattingDeletions))
+			}
+			if s.Pair.Stats.SubmoduleUpdates > 0 {
+				sb.WriteString(fmt.Sprintf("    Submodules:      %d updated (see their own history)\n", s.Pair.Stats.SubmoduleUpdates))
+			}
+			if s.Pair.Stats.Renames > 0 || s.Pair.Stats.Copies > 0 {
+				sb.WriteString(fmt.Sprintf("    Renames/Copies:  %d renamed / %d copied (only edits counted)\n", s.Pair.Stats.Renames, s.Pair.Stats.Copies))
+			}
+			if s.Pair.IsMerge && s.Pair.MergeStats != nil {
+				sb.WriteString(fmt.Sprintf("    Merge Delta:     %d additions / %d deletions introduced by the merge\n", s.Pair.MergeStats.Additions, s.Pair.MergeStats.Deletions))
+			}
+			if len(s.Pair.Stats.Languages) > 0 {
+				sb.WriteString(fmt.Sprintf("    Languages:       %s\n", formatLanguages(s.Pair.Stats.Languages)))
+			}
+			writeTopFiles(sb, s.Pair.Stats)
 			sb.WriteString(fmt.Sprintf("    Time Delta:      %s\n", detector.FormatTimeDelta(s.Pair.TimeDelta)))
 			if s.AdditionVelocity != nil {
 				sb.WriteString(fmt.Sprintf("    Add Velocity:    %.2f additions/min\n", s.AdditionVelocity.LOCPerMinute))
@@ -78,6 +177,9 @@ func (r *TextReporter) Generate(data *ReportData) (string, error) {
 				sb.WriteString(fmt.Sprintf("    Del Velocity:    %.2f deletions/min\n", s.DeletionVelocity.LOCPerMinute))
 			}
 			sb.WriteString(fmt.Sprintf("    Message:         %s\n", truncate(s.Pair.Current.Message, 60)))
+			if s.AISignature != "" {
+				sb.WriteString(fmt.Sprintf("    AI Signature:    %s\n", s.AISignature))
+			}
 			sb.WriteString("    Reasons:\n")
 			for _, reason := range s.Reasons {
 				sb.WriteString(fmt.Sprintf("      - %s\n", reason))
@@ -85,8 +187,27 @@ func (r *TextReporter) Generate(data *ReportData) (string, error) {
 			sb.WriteString("\n")
 		}
 	}
+}
 
-	return sb.String(), nil
+func writeSubmodule(sb *strings.Builder, sub *SubmoduleReport) {
+	header := "Submodule: " + sub.Path
+	if sub.Name != sub.Path {
+		header += " (" + sub.Name + ")"
+	}
+	sb.WriteString(header + "\n")
+	sb.WriteString(strings.Repeat("~", len(header)) + "\n")
+	if sub.ShallowCommits > 0 {
+		sb.WriteString(fmt.Sprintf("NOTE: History is truncated (shallow clone). %d boundary commit(s) were not analyzed.\n", sub.ShallowCommits))
+	}
+	sb.WriteString(fmt.Sprintf("Total Commits:         %d\n", sub.Stats.TotalCommits))
+	sb.WriteString(fmt.Sprintf("Commit Pairs:          %d\n", sub.Stats.TotalCommitPairs))
+	sb.WriteString(fmt.Sprintf("Unique Authors:        %d\n", sub.Stats.UniqueAuthors))
+	sb.WriteString(fmt.Sprintf("Lines of Code:         +%d / -%d lines (filtered)\n", sub.Stats.TotalLOCAdded, sub.Stats.TotalLOCDeleted))
+	sb.WriteString(fmt.Sprintf("Median Velocity:       %.2f LOC/min\n\n", sub.Stats.MedianVelocity))
+	writeSuspicious(sb, sub.Suspicious)
+	if len(sub.Suspicious) == 0 {
+		sb.WriteString("\n")
+	}
 }
 
 func formatDuration(d time.Duration) string {
@@ -103,3 +224,93 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+func writeTopFiles(sb *strings.Builder, stats *git.DiffStats) {
+	if len(stats.Files) == 0 {
+		return
+	}
+
+	sb.WriteString("    Top Files:\n")
+	for _, f := range stats.TopFiles(topFilesLimit) {
+		name := f.Path
+		if f.OldPath != "" {
+			name = f.OldPath + " -> " + f.Path
+		}
+
+		var notes []string
+		if f.Status != git.FileModified {
+			notes = append(notes, string(f.Status))
+		}
+		if f.Submodule {
+			notes = append(notes, "submodule")
+		}
+		if f.Excluded {
+			notes = append(notes, "excluded")
+		}
+		if len(notes) > 0 {
+			name += " (" + strings.Join(notes, ", ") + ")"
+		}
+
+		sb.WriteString(fmt.Sprintf("      %-16s %s\n", fmt.Sprintf("+%d / -%d", f.Additions, f.Deletions), name))
+	}
+	if more := len(stats.Files) - topFilesLimit; more > 0 {
+		sb.WriteString(fmt.Sprintf("      ... and %d more file(s)\n", more))
+	}
+}
+
+// languagesByChurn orders languages by changed lines, most first.
+func languagesByChurn(languages map[string]*metrics.LanguageStats) []*metrics.LanguageStats {
+	sorted := make([]*metrics.LanguageStats, 0, len(languages))
+	for _, lang := range languages {
+		sorted = append(sorted, lang)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ci := sorted[i].LOCAdded + sorted[i].LOCDeleted
+		cj := sorted[j].LOCAdded + sorted[j].LOCDeleted
+		if ci != cj {
+			return ci > cj
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+func formatLanguages(languages map[string]git.LanguageStats) string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci := languages[names[i]].Additions + languages[names[i]].Deletions
+		cj := languages[names[j]].Additions + languages[names[j]].Deletions
+		if ci != cj {
+			return ci > cj
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s +%d/-%d", name, languages[name].Additions, languages[name].Deletions)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func formatSignature(sig *git.Signature) string {
+	if sig == nil {
+		return "none"
+	}
+
+	desc := string(sig.Format)
+	if sig.KeyID != "" {
+		desc += " key " + sig.KeyID
+	}
+	desc += " (" + string(sig.Status)
+	if sig.Signer != "" {
+		desc += ", " + sig.Signer
+	}
+
+	return desc + ")"
+}
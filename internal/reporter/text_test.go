@@ -1,6 +1,7 @@
 package reporter
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -177,6 +178,434 @@ func TestTextReporter_Generate(t *testing.T) {
 			t.Fatal("Generate() returned empty output")
 		}
 	})
+
+	t.Run("generates report with merge commits", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:   &git.Commit{Hash: "previous123"},
+						Current:    &git.Commit{Hash: "merge1234567", Timestamp: now},
+						TimeDelta:  5 * time.Minute,
+						Stats:      &git.DiffStats{Additions: 900},
+						IsMerge:    true,
+						MergeStats: &git.DiffStats{Additions: 120, Deletions: 4},
+					},
+					Reasons: []string{"Merge introduces 120 additions not present in any parent (threshold: 100 lines)"},
+				},
+			},
+			Stats: &metrics.RepositoryStats{
+				MergeCommitPairs: 1,
+				MergeLOCAdded:    120,
+				MergeLOCDeleted:  4,
+			},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"Merge Commits (Lines Introduced by Merges):",
+			"Merges:              1",
+			"Merge Delta:     120 additions / 4 deletions introduced by the merge",
+			"Merge introduces 120 additions",
+		}
+
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %s", expected)
+			}
+		}
+	})
+
+	t.Run("lists refs containing suspicious commits", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous: &git.Commit{Hash: "previous123"},
+						Current: &git.Commit{
+							Hash:      "feature12345",
+							Timestamp: now,
+							Refs:      []string{"refs/heads/feature/a", "refs/heads/feature/b"},
+						},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+		if !contains(output, "Refs:            refs/heads/feature/a, refs/heads/feature/b") {
+			t.Errorf("Output missing refs line:\n%s", output)
+		}
+	})
+
+	t.Run("shows renames and copies", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "rename123456", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, Renames: 2, Copies: 1},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+		if !contains(output, "Renames/Copies:  2 renamed / 1 copied (only edits counted)") {
+			t.Errorf("Output missing renames line:\n%s", output)
+		}
+	})
+
+	t.Run("shows formatting-only lines", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "format123456", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, FormattingAdditions: 450, FormattingDeletions: 440},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+		if !contains(output, "Formatting:      450 additions / 440 deletions only change whitespace") {
+			t.Errorf("Output missing formatting line:\n%s", output)
+		}
+	})
+
+	t.Run("shows binary files", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "binary123456", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 500, BinaryFiles: 3, BinarySizeDelta: 20480},
+					},
+					Reasons: []string{"Suspicious commit size: 500 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+		if !contains(output, "Binary Files:    3 (+20480 bytes)") {
+			t.Errorf("Output missing binary files line:\n%s", output)
+		}
+	})
+
+	t.Run("lists top files", func(t *testing.T) {
+		files := []git.FileStats{
+			{Path: "gen/api.pb.go", Status: git.FileAdded, Additions: 900, Excluded: true},
+			{Path: "main.go", Status: git.FileModified, Additions: 400, Deletions: 12},
+			{Path: "pkg/util.go", OldPath: "util.go", Status: git.FileModified, Additions: 3},
+		}
+		for i := 0; i < 4; i++ {
+			files = append(files, git.FileStats{Path: fmt.Sprintf("small%d.go", i), Status: git.FileModified, Additions: 1})
+		}
+
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "files1234567", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 407, Files: files},
+					},
+					Reasons: []string{"Suspicious commit size: 407 additions (threshold: 100 lines)"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"Top Files:",
+			"+400 / -12       main.go\n",
+			"+3 / -0          util.go -> pkg/util.go\n",
+			"... and 2 more file(s)",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %q\n%s", expected, output)
+			}
+		}
+		if contains(output, "gen/api.pb.go") {
+			t.Errorf("Excluded file should rank below filtered ones:\n%s", output)
+		}
+	})
+
+	t.Run("shows language breakdown", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "lang12345678", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats: &git.DiffStats{Additions: 420, Languages: map[string]git.LanguageStats{
+							"Markdown": {Additions: 20},
+							"Go":       {Additions: 400, Deletions: 12},
+						}},
+					},
+					Reasons: []string{"Suspicious Go commit size: 400 additions (threshold: 200 lines)"},
+				},
+			},
+			Stats: &metrics.RepositoryStats{Languages: map[string]*metrics.LanguageStats{
+				"Go":   {Name: "Go", CommitCount: 3, LOCAdded: 900, LOCDeleted: 40},
+				"YAML": {Name: "YAML", CommitCount: 1, LOCAdded: 10},
+			}},
+			Thresholds: &detector.Thresholds{Languages: map[string]detector.LanguageThresholds{
+				"go": {SuspiciousAdditions: 200},
+			}},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"Lines of Code by Language (Filtered):\n  Go:                  +900 / -40 lines in 3 commit(s)\n  YAML:",
+			"Per Language (0 = disabled):\n  go:                  200 additions, 0 deletions",
+			"Languages:       Go +400/-12, Markdown +20/-0",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %q\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("shows AI signature", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "signed123456", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats:     &git.DiffStats{Additions: 5},
+					},
+					Reasons:     []string{`AI tool signature in commit message (high confidence): "Generated with ChatGPT"`},
+					AISignature: "Generated with ChatGPT",
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100, AISignatures: []string{"a", "b"}},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"AI Signatures:          2 pattern(s) (0 = disabled)",
+			"AI Signature:    Generated with ChatGPT",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %q\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("shows signatures", func(t *testing.T) {
+		pair := func(hash string, sig *git.Signature) *detector.SuspiciousCommit {
+			return &detector.SuspiciousCommit{
+				Pair: &git.CommitPair{
+					Previous:  &git.Commit{Hash: "previous123"},
+					Current:   &git.Commit{Hash: hash, Timestamp: now, Signature: sig},
+					TimeDelta: 5 * time.Minute,
+					Stats:     &git.DiffStats{Additions: 500},
+				},
+				Reasons: []string{"Large commit"},
+			}
+		}
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				pair("unsigned1234", nil),
+				pair("signed123456", &git.Signature{Format: git.SignatureOpenPGP, KeyID: "0123456789ABCDEF", Status: git.SignatureGood, Signer: "Test User <test@example.com>"}),
+			},
+			Stats:        &metrics.RepositoryStats{},
+			Thresholds:   &detector.Thresholds{SuspiciousAdditions: 100},
+			OnlyUnsigned: true,
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"Commits whose signature verified against the keyring are not listed.",
+			"Signature:       none",
+			"Signature:       gpg key 0123456789ABCDEF (good, Test User <test@example.com>)",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %q\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("shows submodules", func(t *testing.T) {
+		data := &ReportData{
+			Suspicious: []*detector.SuspiciousCommit{
+				{
+					Pair: &git.CommitPair{
+						Previous:  &git.Commit{Hash: "previous123"},
+						Current:   &git.Commit{Hash: "bump1234567", Timestamp: now},
+						TimeDelta: 5 * time.Minute,
+						Stats: &git.DiffStats{SubmoduleUpdates: 1, Files: []git.FileStats{
+							{Path: "vendor/lib", Status: git.FileModified, Submodule: true},
+						}},
+					},
+					Reasons: []string{"Time between commits too short"},
+				},
+			},
+			Stats:      &metrics.RepositoryStats{},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+			Submodules: []*SubmoduleReport{
+				{
+					Name:  "lib",
+					Path:  "vendor/lib",
+					Stats: &metrics.RepositoryStats{TotalCommits: 12, TotalLOCAdded: 900},
+					Suspicious: []*detector.SuspiciousCommit{
+						{
+							Pair: &git.CommitPair{
+								Previous:  &git.Commit{Hash: "libprev123"},
+								Current:   &git.Commit{Hash: "libbig12345", Timestamp: now},
+								TimeDelta: time.Minute,
+								Stats:     &git.DiffStats{Additions: 890},
+							},
+							Reasons: []string{"Suspicious commit size: 890 additions (threshold: 100 lines)"},
+						},
+					},
+				},
+				{Name: "docs", Path: "docs", Stats: &metrics.RepositoryStats{TotalCommits: 3}},
+			},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"Submodules:      1 updated (see their own history)",
+			"+0 / -0          vendor/lib (submodule)",
+			"SUBMODULES",
+			"Submodule: vendor/lib (lib)",
+			"Total Commits:         12",
+			"Lines of Code:         +900 / -0 lines (filtered)",
+			"[1] Commit: libbig1",
+			"Suspicious commit size: 890 additions",
+			"Submodule: docs\n",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %q\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("notes truncated history", func(t *testing.T) {
+		data := &ReportData{
+			Stats:          &metrics.RepositoryStats{},
+			Thresholds:     &detector.Thresholds{SuspiciousAdditions: 100},
+			ShallowCommits: 2,
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+		if !contains(output, "NOTE: History is truncated (shallow clone). 2 boundary commit(s)") {
+			t.Errorf("Output missing shallow note:\n%s", output)
+		}
+	})
+
+	t.Run("shows incremental run", func(t *testing.T) {
+		data := &ReportData{
+			Stats:      &metrics.RepositoryStats{TotalCommits: 120},
+			Thresholds: &detector.Thresholds{SuspiciousAdditions: 100},
+			Incremental: &IncrementalRun{
+				NewCommits:  7,
+				PreviousRun: time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC),
+			},
+		}
+
+		reporter := &TextReporter{}
+		output, err := reporter.Generate(data)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error = %v", err)
+		}
+
+		expectedStrings := []string{
+			"INCREMENTAL RUN",
+			"Previous Run:          2024-03-01T02:00:00Z",
+			"New Commits:           7",
+		}
+		for _, expected := range expectedStrings {
+			if !contains(output, expected) {
+				t.Errorf("Output missing expected string: %s", expected)
+			}
+		}
+	})
 }
 
 func TestTruncate(t *testing.T) {
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/anisimov-anthony/vibector/internal/metrics"
+)
+
+// Version must be bumped whenever the stored statistics change meaning, so
+// that old state files are no longer merged with new results.
+const Version = 1
+
+// State records what an incremental analysis has covered so far: the tip
+// of every analyzed ref and the statistics accumulated over all runs.
+type State struct {
+	Version    int    `json:"version"`
+	Repository string `json:"repository"`
+
+	// Fingerprint identifies the settings the statistics were accumulated
+	// with. Statistics of runs with different settings cannot be merged.
+	Fingerprint string                   `json:"fingerprint"`
+	Tips        map[string]string        `json:"tips"`
+	Stats       *metrics.RepositoryStats `json:"stats"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+}
+
+// Load reads a state file. A missing file yields nil without an error, as
+// for a first run.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported state file version %d (expected %d), remove %s to start over", s.Version, Version, path)
+	}
+
+	return &s, nil
+}
+
+// Save writes the state file atomically.
+func Save(path string, s *State) error {
+	s.Version = Version
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".vibector-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
+
+// Revisions returns the previously analyzed tips, whose history a new run
+// can skip.
+func (s *State) Revisions() []string {
+	if s == nil {
+		return nil
+	}
+
+	revisions := make([]string, 0, len(s.Tips))
+	for _, hash := range s.Tips {
+		revisions = append(revisions, hash)
+	}
+	sort.Strings(revisions)
+
+	return revisions
+}
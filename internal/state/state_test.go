@@ -0,0 +1,90 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anisimov-anthony/vibector/internal/metrics"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	updated := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	s := &State{
+		Repository: "/repos/app",
+		Tips:       map[string]string{"refs/heads/main": "abc123", "refs/heads/dev": "def456"},
+		Stats: &metrics.RepositoryStats{
+			TotalCommits: 10,
+			Authors: map[string]*metrics.AuthorStats{
+				"alice@example.com": {Email: "alice@example.com", CommitCount: 4, Velocities: []float64{1.5, 3}},
+			},
+			Velocities: []float64{1.5, 3, 7},
+		},
+		UpdatedAt: updated,
+	}
+
+	if err := Save(path, s); err != nil {
+		t.Fatalf("Save() unexpected error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	if loaded.Version != Version {
+		t.Errorf("Version = %d, want %d", loaded.Version, Version)
+	}
+	if loaded.Repository != s.Repository || !loaded.UpdatedAt.Equal(updated) {
+		t.Errorf("loaded = %+v, want repository %s updated %v", loaded, s.Repository, updated)
+	}
+	if loaded.Stats.TotalCommits != 10 || len(loaded.Stats.Velocities) != 3 {
+		t.Errorf("Stats = %+v, want 10 commits and 3 velocities", loaded.Stats)
+	}
+	if a := loaded.Stats.Authors["alice@example.com"]; a == nil || a.CommitCount != 4 || len(a.Velocities) != 2 {
+		t.Errorf("author = %+v, want 4 commits and 2 velocities", a)
+	}
+
+	revisions := loaded.Revisions()
+	if len(revisions) != 2 || revisions[0] != "abc123" || revisions[1] != "def456" {
+		t.Errorf("Revisions() = %v, want [abc123 def456]", revisions)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		s, err := Load(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatalf("Load() unexpected error = %v", err)
+		}
+		if s != nil {
+			t.Errorf("Load() = %+v, want nil", s)
+		}
+		if s.Revisions() != nil {
+			t.Error("nil state should have no revisions")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+			t.Fatalf("Failed to write state file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Load() expected error for invalid JSON")
+		}
+	})
+
+	t.Run("other version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(path, []byte(`{"version": 999}`), 0o600); err != nil {
+			t.Fatalf("Failed to write state file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Load() expected error for unsupported version")
+		}
+	})
+}
@@ -164,7 +164,7 @@ func TestFullWorkflow(t *testing.T) {
 			t.Fatalf("Failed to create detector: %v", err)
 		}
 
-		suspicious := d.DetectSuspicious(result.CommitPairs, stats)
+		suspicious := d.DetectSuspicious(nil, result.CommitPairs, stats)
 		if len(suspicious) == 0 {
 			t.Error("Expected to detect at least one suspicious commit")
 		}
@@ -346,7 +346,7 @@ func TestEndToEndScenarios(t *testing.T) {
 			MaxAdditionsPerMin:  100.0,
 		}
 		d, _ := detector.New(thresholds)
-		suspicious := d.DetectSuspicious(result.CommitPairs, stats)
+		suspicious := d.DetectSuspicious(nil, result.CommitPairs, stats)
 
 		if len(suspicious) == 0 {
 			t.Error("Should detect suspicious commits in AI-like burst")
@@ -411,7 +411,7 @@ func TestEndToEndScenarios(t *testing.T) {
 			MinTimeDeltaSeconds: 10,    // Less than our 2 minutes
 		}
 		d, _ := detector.New(thresholds)
-		suspicious := d.DetectSuspicious(result.CommitPairs, stats)
+		suspicious := d.DetectSuspicious(nil, result.CommitPairs, stats)
 
 		if len(suspicious) > 0 {
 			t.Errorf("Should not detect suspicious commits for normal activity, but found %d", len(suspicious))